@@ -0,0 +1,166 @@
+package api
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/banachtech/spotted-zebra/calendar"
+	"github.com/banachtech/spotted-zebra/payoff"
+	"gonum.org/v1/gonum/stat"
+)
+
+// Business days per year used to annualise daily returns
+const tradingDays = 252
+
+// Performance of a rolling issuance strategy that buys every note of the backtest at its price on
+// its issue date and holds it to redemption. The NAV is that of a portfolio rebalanced daily to an
+// equal weight in each live note. Notes are carried at cost: coupons are returned on their payment
+// dates and the gain or loss of principal on the redemption date. Excess returns are over the
+// discount rate of the pricer.
+type backtestAnalytics struct {
+	AnnualisedReturn float64    `json:"annualised_return"`
+	Volatility       float64    `json:"volatility"`
+	Sharpe           float64    `json:"sharpe"`
+	Sortino          float64    `json:"sortino"`
+	MaxDrawdown      float64    `json:"max_drawdown"`
+	AutocallRate     float64    `json:"autocall_rate"`
+	KnockInRate      float64    `json:"knock_in_rate"`
+	AverageLife      float64    `json:"average_life"`
+	NAV              []navPoint `json:"nav"`
+}
+
+// NAV of the rolling issuance strategy at the close of a business day
+type navPoint struct {
+	Date string  `json:"date"`
+	NAV  float64 `json:"nav"`
+}
+
+// Analytics of the notes of a backtest, in issue date order
+func newBacktestAnalytics(notes []backtestNote) backtestAnalytics {
+	var out backtestAnalytics
+	if len(notes) == 0 {
+		return out
+	}
+
+	life := 0.0
+	for _, v := range notes {
+		if v.AutocallDate != "" {
+			out.AutocallRate++
+		}
+		if v.KnockedIn {
+			out.KnockInRate++
+		}
+		life += payoff.YearFrac(v.issued, v.redeemed)
+	}
+	n := float64(len(notes))
+	out.AutocallRate /= n
+	out.KnockInRate /= n
+	out.AverageLife = life / n
+
+	days, nav := rollingNAV(notes)
+	if len(days) < 2 {
+		return out
+	}
+	out.NAV = make([]navPoint, len(days))
+	for i, d := range days {
+		out.NAV[i] = navPoint{Date: d.Format(Layout), NAV: nav[i]}
+	}
+	out.MaxDrawdown = maxDrawdown(nav)
+	if years := payoff.YearFrac(days[0], days[len(days)-1]); years > 0 && nav[len(nav)-1] > 0 {
+		out.AnnualisedReturn = math.Pow(nav[len(nav)-1], 1/years) - 1
+	}
+
+	rf := payoff.Rate / tradingDays
+	excess := make([]float64, len(nav)-1)
+	downside := 0.0
+	for i := range excess {
+		excess[i] = nav[i+1]/nav[i] - 1 - rf
+		downside += math.Pow(math.Min(excess[i], 0), 2)
+	}
+	mean, std := stat.MeanStdDev(excess, nil)
+	downside = math.Sqrt(downside / float64(len(excess)))
+	out.Volatility = std * math.Sqrt(tradingDays)
+	if std > 0 {
+		out.Sharpe = mean / std * math.Sqrt(tradingDays)
+	}
+	if downside > 0 {
+		out.Sortino = mean / downside * math.Sqrt(tradingDays)
+	}
+	return out
+}
+
+// NAV of the rolling issuance strategy on the business days of the default calendar from the
+// first issue date to the last redemption, starting at 1. A note enters the portfolio at the close
+// of its issue date. Its return on a day is the cash it pays that day over its price, less its
+// price on redemption.
+func rollingNAV(notes []backtestNote) ([]time.Time, []float64) {
+	start, end := notes[0].issued, notes[0].redeemed
+	for _, v := range notes {
+		if v.issued.Before(start) {
+			start = v.issued
+		}
+		if v.redeemed.After(end) {
+			end = v.redeemed
+		}
+	}
+	days, err := calendar.Default().BusinessDates(start, end)
+	if err != nil {
+		return nil, nil
+	}
+	day := func(d time.Time) int {
+		return sort.Search(len(days)-1, func(i int) bool { return !days[i].Before(d) })
+	}
+
+	// Sum of the returns of live notes and change in the number of live notes on each day
+	ret := make([]float64, len(days)+1)
+	live := make([]int, len(days)+1)
+	for _, v := range notes {
+		if v.Price <= 0 {
+			continue
+		}
+		s := day(v.issued)
+		e := day(v.redeemed)
+		if e <= s {
+			e = s + 1
+		}
+		if e >= len(days) {
+			continue
+		}
+		live[s+1]++
+		live[e+1]--
+		for _, cf := range v.cashflows {
+			i := day(cf.Paid())
+			if i <= s {
+				i = s + 1
+			}
+			if i > e {
+				i = e
+			}
+			ret[i] += cf.Amount / v.Price
+		}
+		ret[e] -= 1
+	}
+
+	nav := make([]float64, len(days))
+	nav[0] = 1
+	n := 0
+	for i := 1; i < len(days); i++ {
+		n += live[i]
+		nav[i] = nav[i-1]
+		if n > 0 {
+			nav[i] *= 1 + ret[i]/float64(n)
+		}
+	}
+	return days, nav
+}
+
+// Largest fall of a NAV from its previous peak, as a negative fraction of the peak
+func maxDrawdown(nav []float64) float64 {
+	out, peak := 0.0, nav[0]
+	for _, v := range nav {
+		peak = math.Max(peak, v)
+		out = math.Min(out, v/peak-1)
+	}
+	return out
+}
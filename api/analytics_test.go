@@ -0,0 +1,70 @@
+package api
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/banachtech/spotted-zebra/payoff"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBacktestAnalytics(t *testing.T) {
+	t0 := time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC)
+	t1 := time.Date(2023, 2, 3, 0, 0, 0, 0, time.UTC)
+	t2 := time.Date(2023, 3, 3, 0, 0, 0, 0, time.UTC)
+
+	notes := []backtestNote{
+		newBacktestNote(t0, 1.0, []payoff.Cashflow{
+			{Date: t1, Type: payoff.FixedCoupon, Amount: 0.02},
+			{Date: t2, Type: payoff.KnockOut},
+			{Date: t2, Type: payoff.Redemption, Amount: 1.0},
+		}),
+		newBacktestNote(t0.AddDate(0, 0, 1), 1.0, []payoff.Cashflow{
+			{Date: t1, Type: payoff.KnockIn},
+			{Date: t2, Type: payoff.Redemption, Amount: 0.5},
+		}),
+	}
+	a := newBacktestAnalytics(notes)
+
+	require.Equal(t, 0.5, a.AutocallRate)
+	require.Equal(t, 0.5, a.KnockInRate)
+	require.InDelta(t, 58.5/365, a.AverageLife, 1e-12)
+
+	require.Equal(t, navPoint{Date: "2023-01-03", NAV: 1}, a.NAV[0])
+	require.Equal(t, "2023-03-03", a.NAV[len(a.NAV)-1].Date)
+	for _, v := range a.NAV {
+		switch {
+		case v.Date < "2023-02-03":
+			require.Equal(t, 1.0, v.NAV, v.Date)
+		case v.Date < "2023-03-03":
+			require.InDelta(t, 1.01, v.NAV, 1e-12, v.Date)
+		default:
+			require.InDelta(t, 0.7575, v.NAV, 1e-12, v.Date)
+		}
+	}
+	require.InDelta(t, -0.25, a.MaxDrawdown, 1e-12)
+	require.InDelta(t, math.Pow(0.7575, 365.0/59)-1, a.AnnualisedReturn, 1e-12)
+	require.Greater(t, a.Volatility, 0.0)
+	require.Less(t, a.Sharpe, 0.0)
+	require.Less(t, a.Sortino, 0.0)
+
+	require.Equal(t, backtestAnalytics{}, newBacktestAnalytics(nil))
+}
+
+func TestMaxDrawdown(t *testing.T) {
+	testCases := []struct {
+		name string
+		nav  []float64
+		want float64
+	}{
+		{name: "RISING", nav: []float64{1, 1.1, 1.2}, want: 0},
+		{name: "FALLING", nav: []float64{1, 0.9, 0.8}, want: -0.2},
+		{name: "RECOVERY", nav: []float64{1, 1.25, 1, 1.5, 1.2}, want: -0.2},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.InDelta(t, tc.want, maxDrawdown(tc.nav), 1e-12)
+		})
+	}
+}
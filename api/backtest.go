@@ -17,11 +17,37 @@ import (
 	"github.com/gin-gonic/gin"
 	"golang.org/x/time/rate"
 	"gonum.org/v1/gonum/mat"
-	"gonum.org/v1/gonum/stat"
 )
 
 var Backtestlimiters = make(map[string]*rate.Limiter)
 
+// Backtest modes
+const (
+	// Compare the price at inception with the payout of a simulated path
+	SimulatedBacktest = "simulated"
+	// Compare the price at inception with the payout on the realised closing prices
+	RealisedBacktest = "realised"
+)
+
+// Issuance frequency of a rolling backtest
+const (
+	// Issue a note on every date with market data
+	DailyStride = "daily"
+	// Issue a note on the first date of each week
+	WeeklyStride = "weekly"
+	// Issue a note on the first date of each month
+	MonthlyStride = "monthly"
+)
+
+type backtestRequest struct {
+	pricerRequest
+	Mode      string `json:"mode" binding:"omitempty,oneof=simulated realised"`
+	Format    string `json:"format" binding:"omitempty,oneof=json csv"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	Stride    string `json:"stride" binding:"omitempty,oneof=daily weekly monthly"`
+}
+
 func getBacktestLimiter(userID string) *rate.Limiter {
 	limiter, ok := Backtestlimiters[userID]
 	if !ok {
@@ -33,8 +59,7 @@ func getBacktestLimiter(userID string) *rate.Limiter {
 }
 
 func (server *Server) backtest(c *gin.Context) {
-	var req pricerRequest
-	var wg sync.WaitGroup
+	var req backtestRequest
 
 	prefix, exists := c.Get("prefix")
 	if !exists {
@@ -71,7 +96,13 @@ func (server *Server) backtest(c *gin.Context) {
 	}
 	req.Stocks = filterStocks
 
-	result, err := server.store.GetBacktestValues(c)
+	period, err := backtestPeriod(req)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	result, err := server.store.GetBacktestValues(c, period)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.AbortWithStatusJSON(http.StatusNotFound, errorResponse(err))
@@ -83,81 +114,134 @@ func (server *Server) backtest(c *gin.Context) {
 	}
 
 	dates, models, fixings, means, corrMatrix := backtestConstructor(result, filterStocks)
+	dates = issueDates(dates, req.Stride)
+	if len(dates) == 0 {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"status": http.StatusNotFound, "msg": "No market data in the backtest period"})
+		return
+	}
+
+	if req.Mode == RealisedBacktest {
+		server.realisedBacktest(c, req, dates, filterStocks, models, fixings, means, corrMatrix)
+		return
+	}
+
+	notes, err := simulatedNotes(dates, filterStocks, req.pricerRequest, fixings, means, models, corrMatrix)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest, "msg": fmt.Sprintf("Failed compute FCN payout: %s", err)})
+		return
+	}
+	backtestResponse(c, req.Format, notes, gin.H{})
+}
+
+// Backtest notes issued on each date against the realised closes through their maturity.
+func (server *Server) realisedBacktest(c *gin.Context, req backtestRequest, dates, stocks []string, models map[string]map[string]mc.Model, fixings, means map[string]map[string]float64, corrMatrix map[string]*mat.SymDense) {
+	rows, err := server.store.GetCloses(c, dates[0])
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	closes, err := newCloseSeries(rows)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	notes, live, err := realisedNotes(dates, stocks, req.pricerRequest, fixings, means, models, corrMatrix, closes)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest, "msg": fmt.Sprintf("Failed compute realised payout: %s", err)})
+		return
+	}
+	if len(notes) == 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest, "msg": "No note has matured in the backtest period"})
+		return
+	}
+
+	backtestResponse(c, req.Format, notes, gin.H{"notes": len(notes), "live": live})
+}
+
+// Range of dates of the backtest, both included. Either end may be left open.
+func backtestPeriod(req backtestRequest) (db.GetBacktestValuesParams, error) {
+	out := db.GetBacktestValuesParams{Start: req.StartDate, End: req.EndDate}
+	for _, v := range []string{req.StartDate, req.EndDate} {
+		if v == "" {
+			continue
+		}
+		if _, err := time.Parse(Layout, v); err != nil {
+			return out, fmt.Errorf("invalid backtest date: %s", v)
+		}
+	}
+	if out.End == "" {
+		out.End = "9999-12-31"
+	}
+	if out.End < out.Start {
+		return out, errors.New("end date must be later than start date")
+	}
+	return out, nil
+}
 
-	// prices := map[string]float64{}
-	errCh := make(chan error, len(dates))
-	pnlCh := make(chan float64, len(dates))
-	dateCh := make(chan string, len(dates))
-	// defer close(dateCh)
-	// defer close(errCh)
-	// defer close(pnlCh)
-	var profit []float64
+// Issue dates of a rolling backtest: every date for a daily stride, or the first date in each week
+// or month for a weekly or monthly stride.
+func issueDates(dates []string, stride string) []string {
+	if stride == "" || stride == DailyStride {
+		return dates
+	}
+	var out []string
+	prev := ""
+	for _, v := range dates {
+		d, err := time.Parse(Layout, v)
+		if err != nil {
+			continue
+		}
+		key := d.Format("2006-01")
+		if stride == WeeklyStride {
+			y, w := d.ISOWeek()
+			key = fmt.Sprintf("%d-W%d", y, w)
+		}
+		if key != prev {
+			out = append(out, v)
+			prev = key
+		}
+	}
+	return out
+}
+
+// Price a note issued on each backtest date and pay it out on a simulated path.
+func simulatedNotes(dates []string, stocks []string, arg pricerRequest, fixings, means map[string]map[string]float64, models map[string]map[string]mc.Model, corrMatrix map[string]*mat.SymDense) ([]backtestNote, error) {
+	var wg sync.WaitGroup
+	notes := make([]backtestNote, len(dates))
+	errs := make([]error, len(dates))
 	for t := range dates {
 		wg.Add(1)
 		go func(t int) {
 			defer wg.Done()
-			p, err := fcnPricer(filterStocks, req, fixings[dates[t]], means[dates[t]], fixings[dates[t]], models[dates[t]], corrMatrix[dates[t]])
+			d := dates[t]
+			arg := arg
+			arg.ValuationDate = d
+			p, err := fcnPricer(stocks, arg, fixings[d], means[d], fixings[d], models[d], corrMatrix[d])
 			if err != nil {
-				errCh <- err
-				pnlCh <- math.NaN()
-				dateCh <- dates[t]
+				errs[t] = err
 				return
 			}
-
-			// prices[dates[t]] = p
-
-			payout, err := fcnPayout(dates[t], filterStocks, req, fixings[dates[t]], means[dates[t]], fixings[dates[t]], models[dates[t]], corrMatrix[dates[t]])
+			cfs, err := simulatedCashflows(d, stocks, arg, fixings[d], means[d], fixings[d], models[d], corrMatrix[d])
 			if err != nil {
-				errCh <- err
-				pnlCh <- math.NaN()
-				dateCh <- dates[t]
+				errs[t] = err
 				return
 			}
-
-			pnl := payout - p
-			if math.IsNaN(pnl) {
-				errCh <- errors.New("return is NaN")
-				pnlCh <- math.NaN()
-				dateCh <- dates[t]
-				return
+			t0, _ := time.Parse(Layout, d)
+			notes[t] = newBacktestNote(t0, p, cfs)
+			if math.IsNaN(notes[t].PnL) {
+				errs[t] = errors.New("return is NaN")
 			}
-			errCh <- nil
-			pnlCh <- pnl
-			dateCh <- dates[t]
 		}(t)
 	}
-
 	wg.Wait()
-	close(dateCh)
-	close(errCh)
-	close(pnlCh)
 
-	rollout := map[string]float64{}
-	for range dates {
-		err := <-errCh
+	for _, err := range errs {
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest, "msg": fmt.Sprintf("Failed compute FCN payout: %s", err)})
-			return
+			return nil, err
 		}
-		pnl := <-pnlCh
-		if !math.IsNaN(pnl) {
-			profit = append(profit, pnl)
-		}
-		date := <-dateCh
-		rollout[date] = pnl
 	}
-
-	var sortedRet []float64
-	for t := range dates {
-		sortedRet = append(sortedRet, rollout[dates[t]])
-	}
-
-	maxDrawDown := maxDrawDown(sortedRet)
-
-	mean, std := stat.MeanStdDev(profit, nil)
-	min, max := minmax(profit)
-
-	c.JSON(http.StatusOK, gin.H{"mean": mean, "std": std, "min": min, "max": max, "max_drawdown": maxDrawDown})
+	return notes, nil
 }
 
 func backtestConstructor(target db.GetBacktestValuesResult, filterStocks []string) ([]string, map[string]map[string]mc.Model, map[string]map[string]float64, map[string]map[string]float64, map[string]*mat.SymDense) {
@@ -235,6 +319,17 @@ func backtestConstructor(target db.GetBacktestValuesResult, filterStocks []strin
 }
 
 func fcnPayout(date string, stocks []string, arg pricerRequest, fixings, means, px map[string]float64, models map[string]mc.Model, corrMatrix *mat.SymDense) (float64, error) {
+	cfs, err := simulatedCashflows(date, stocks, arg, fixings, means, px, models, corrMatrix)
+	if err != nil {
+		return math.NaN(), err
+	}
+	tNow, _ := time.Parse(Layout, date)
+	return payoff.PV(cfs, tNow), nil
+}
+
+// Cashflows of a note issued on date along a single simulated path, observed on the schedule of
+// the requested calendars and paid on their payment dates as in the pricer.
+func simulatedCashflows(date string, stocks []string, arg pricerRequest, fixings, means, px map[string]float64, models map[string]mc.Model, corrMatrix *mat.SymDense) ([]payoff.Cashflow, error) {
 	pxRatio := map[string]float64{}
 	var mu []float64
 	for _, v := range stocks {
@@ -242,17 +337,24 @@ func fcnPayout(date string, stocks []string, arg pricerRequest, fixings, means,
 		mu = append(mu, means[v])
 	}
 
-	bsk := mc.NewBasket(models)
+	vols := atmVols(models, float64(arg.Maturity)/12.0)
+	bsk, err := newBasket(stocks, arg, models, vols)
+	if err != nil {
+		return nil, err
+	}
 
 	dz1, dz2, err := distributions(mu, corrMatrix)
 	if err != nil {
-		return math.NaN(), err
+		return nil, err
 	}
 
-	tNow, _ := time.Parse(Layout, date)
-	dates, err := util.GenerateDates(tNow, arg.Maturity, arg.Freq)
+	tNow, err := time.Parse(Layout, date)
 	if err != nil {
-		return math.NaN(), err
+		return nil, err
+	}
+	dates, err := scheduleDates(stocks, arg, tNow)
+	if err != nil {
+		return nil, err
 	}
 
 	n_sims := len(dates["mcdates"]) - 1
@@ -272,11 +374,25 @@ func fcnPayout(date string, stocks []string, arg pricerRequest, fixings, means,
 		}
 	}
 
-	fcn := payoff.NewFCN(stocks, arg.Strike, arg.Cpn, arg.BarrierCpn, arg.FixCpn, arg.KO, arg.KI, arg.KC, arg.Maturity, arg.Freq, arg.IsEuro, dates)
-	path := bsk.Path(stocks, dates["mcdates"], pxRatio, z1, z2)
-	wop := wop(fixings, dates, path)
-	x := fcn.Payout(wop)
-	return x, nil
+	settleFixings, err := settlementFixings(stocks, arg, fixings)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := newPayoff(stocks, arg, settleFixings, vols, dates)
+	if err != nil {
+		return nil, err
+	}
+	if product, err = postponed(stocks, arg, tNow, product, dates); err != nil {
+		return nil, err
+	}
+	pay, err := paymentDates(stocks, arg)
+	if err != nil {
+		return nil, err
+	}
+	product = payoff.NewSettled(product, pay)
+	path := bsk.Path(stocks, product.Dates(), pxRatio, z1, z2)
+	return product.Cashflows(path), nil
 }
 
 func minmax(array []float64) (float64, float64) {
@@ -292,21 +408,3 @@ func minmax(array []float64) (float64, float64) {
 	}
 	return min, max
 }
-
-func maxDrawDown(array []float64) float64 {
-	var rollout []float64
-	x := make([]float64, len(array)+1)
-	x[0] = 1.0
-	for i := 0; i < len(array); i++ {
-		x[i+1] = x[i] * (1.0 + array[i])
-	}
-	for i := 1; i < len(x); i++ {
-		xt := x[i]
-		xPrev := x[:i]
-		_, maxPrevX := minmax(xPrev)
-		r := xt/maxPrevX - 1
-		rollout = append(rollout, r)
-	}
-	maxR, _ := minmax(rollout)
-	return maxR
-}
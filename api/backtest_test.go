@@ -3,16 +3,21 @@ package api
 import (
 	"bytes"
 	"database/sql"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"math"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/banachtech/spotted-zebra/calendar"
 	mockdb "github.com/banachtech/spotted-zebra/db/mock"
 	db "github.com/banachtech/spotted-zebra/db/sqlc"
 	"github.com/banachtech/spotted-zebra/mc"
+	"github.com/banachtech/spotted-zebra/payoff"
+	"github.com/banachtech/spotted-zebra/util"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
@@ -167,8 +172,8 @@ func TestBackTest(t *testing.T) {
 		EmailAddress: "test123@example.com",
 		Prefix:       "dmag_d8K",
 		Token:        "$2a$14$eIWUgPMqNQbpPveJdoQ8sOSw7DY5zBXUP3uUhm31LrfbArv6ZIhXe",
-		GeneratedAt:  "2022-12-30 18:09:35",
-		ExpiredAt:    "2023-06-30 18:09:35",
+		GeneratedAt:  time.Now().Format(Layout2),
+		ExpiredAt:    time.Now().AddDate(1, 0, 0).Format(Layout2),
 	}
 	testCases := []struct {
 		name          string
@@ -200,12 +205,192 @@ func TestBackTest(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
-				store.EXPECT().GetBacktestValues(gomock.Any()).Times(1).Return(values, nil)
+				store.EXPECT().GetBacktestValues(gomock.Any(), gomock.Any()).Times(1).Return(values, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 			},
 		},
+		{
+			name:  "REALISED",
+			token: "dmag_d8K.RGbV3hb3LEwYohYW",
+			body: gin.H{
+				"stocks":               []string{"AAPL", "AVGO", "TSLA"},
+				"strike":               0.80,
+				"autocall_coupon_rate": 0.50,
+				"barrier_coupon_rate":  0.20,
+				"fixed_coupon_rate":    0.20,
+				"knock_out_barrier":    1.05,
+				"knock_in_barrier":     0.70,
+				"coupon_barrier":       0.80,
+				"maturity":             3,
+				"frequency":            1,
+				"isEuro":               true,
+				"mode":                 "realised",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, token string) {
+				authorizationHeader := fmt.Sprintf("%s %s", authorizationTypeBearer, token)
+				request.Header.Set(authorizationHeaderKey, authorizationHeader)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
+				store.EXPECT().GetBacktestValues(gomock.Any(), gomock.Any()).Times(1).Return(values, nil)
+				store.EXPECT().GetCloses(gomock.Any(), gomock.Eq("2022-12-27")).Times(1).Return(dailyCloses([]string{"AAPL", "AVGO", "TSLA"}, "2022-12-27", "2023-04-28", 100, 1.0), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				var res struct {
+					Notes        int            `json:"notes"`
+					Live         int            `json:"live"`
+					AutocallRate float64        `json:"autocall_rate"`
+					KnockInRate  float64        `json:"knock_in_rate"`
+					AverageLife  float64        `json:"average_life"`
+					NAV          []navPoint     `json:"nav"`
+					Series       []backtestNote `json:"series"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, 2, res.Notes)
+				require.Equal(t, 0, res.Live)
+				require.Equal(t, 1.0, res.AutocallRate)
+				require.Equal(t, 0.0, res.KnockInRate)
+				require.Len(t, res.Series, 2)
+				require.Equal(t, "2022-12-27", res.Series[0].Date)
+				require.Equal(t, "2023-01-27", res.Series[0].AutocallDate)
+				require.Greater(t, res.AverageLife, 0.0)
+				require.Equal(t, "2022-12-27", res.NAV[0].Date)
+				require.Equal(t, res.Series[1].RedemptionDate, res.NAV[len(res.NAV)-1].Date)
+			},
+		},
+		{
+			name:  "REALISED_LIVE",
+			token: "dmag_d8K.RGbV3hb3LEwYohYW",
+			body: gin.H{
+				"stocks":               []string{"AAPL", "AVGO", "TSLA"},
+				"strike":               0.80,
+				"autocall_coupon_rate": 0.50,
+				"barrier_coupon_rate":  0.20,
+				"fixed_coupon_rate":    0.20,
+				"knock_out_barrier":    1.05,
+				"knock_in_barrier":     0.70,
+				"coupon_barrier":       0.80,
+				"maturity":             3,
+				"frequency":            1,
+				"isEuro":               true,
+				"mode":                 "realised",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, token string) {
+				authorizationHeader := fmt.Sprintf("%s %s", authorizationTypeBearer, token)
+				request.Header.Set(authorizationHeaderKey, authorizationHeader)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
+				store.EXPECT().GetBacktestValues(gomock.Any(), gomock.Any()).Times(1).Return(values, nil)
+				store.EXPECT().GetCloses(gomock.Any(), gomock.Eq("2022-12-27")).Times(1).Return(dailyCloses([]string{"AAPL", "AVGO", "TSLA"}, "2022-12-27", "2023-03-15", 100, 0.0), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "CSV",
+			token: "dmag_d8K.RGbV3hb3LEwYohYW",
+			body: gin.H{
+				"stocks":               []string{"AAPL", "AVGO", "TSLA"},
+				"strike":               0.80,
+				"autocall_coupon_rate": 0.50,
+				"barrier_coupon_rate":  0.20,
+				"fixed_coupon_rate":    0.20,
+				"knock_out_barrier":    1.05,
+				"knock_in_barrier":     0.70,
+				"coupon_barrier":       0.80,
+				"maturity":             12,
+				"frequency":            3,
+				"isEuro":               true,
+				"format":               "csv",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, token string) {
+				authorizationHeader := fmt.Sprintf("%s %s", authorizationTypeBearer, token)
+				request.Header.Set(authorizationHeaderKey, authorizationHeader)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
+				store.EXPECT().GetBacktestValues(gomock.Any(), gomock.Any()).Times(1).Return(values, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "text/csv", recorder.Header().Get("Content-Type"))
+				rows, err := csv.NewReader(recorder.Body).ReadAll()
+				require.NoError(t, err)
+				require.Len(t, rows, 3)
+				require.Equal(t, []string{"2022-12-27", "2022-12-28"}, []string{rows[1][0], rows[2][0]})
+			},
+		},
+		{
+			name:  "WEEKLY_STRIDE",
+			token: "dmag_d8K.RGbV3hb3LEwYohYW",
+			body: gin.H{
+				"stocks":               []string{"AAPL", "AVGO", "TSLA"},
+				"strike":               0.80,
+				"autocall_coupon_rate": 0.50,
+				"barrier_coupon_rate":  0.20,
+				"fixed_coupon_rate":    0.20,
+				"knock_out_barrier":    1.05,
+				"knock_in_barrier":     0.70,
+				"coupon_barrier":       0.80,
+				"maturity":             12,
+				"frequency":            3,
+				"isEuro":               true,
+				"format":               "csv",
+				"start_date":           "2022-12-01",
+				"end_date":             "2022-12-31",
+				"stride":               "weekly",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, token string) {
+				authorizationHeader := fmt.Sprintf("%s %s", authorizationTypeBearer, token)
+				request.Header.Set(authorizationHeaderKey, authorizationHeader)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
+				store.EXPECT().GetBacktestValues(gomock.Any(), gomock.Eq(db.GetBacktestValuesParams{Start: "2022-12-01", End: "2022-12-31"})).Times(1).Return(values, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				rows, err := csv.NewReader(recorder.Body).ReadAll()
+				require.NoError(t, err)
+				require.Len(t, rows, 2)
+				require.Equal(t, "2022-12-27", rows[1][0])
+			},
+		},
+		{
+			name:  "INVALID_PERIOD",
+			token: "dmag_d8K.RGbV3hb3LEwYohYW",
+			body: gin.H{
+				"stocks":               []string{"AAPL", "AVGO", "TSLA"},
+				"strike":               0.80,
+				"autocall_coupon_rate": 0.50,
+				"barrier_coupon_rate":  0.20,
+				"fixed_coupon_rate":    0.20,
+				"knock_out_barrier":    1.05,
+				"knock_in_barrier":     0.70,
+				"coupon_barrier":       0.80,
+				"maturity":             12,
+				"frequency":            3,
+				"isEuro":               true,
+				"start_date":           "2022-12-31",
+				"end_date":             "2022-12-01",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, token string) {
+				authorizationHeader := fmt.Sprintf("%s %s", authorizationTypeBearer, token)
+				request.Header.Set(authorizationHeaderKey, authorizationHeader)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
+				store.EXPECT().GetBacktestValues(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
 		{
 			name:  "ERROR_BINDING",
 			token: "dmag_d8K.RGbV3hb3LEwYohYW",
@@ -228,7 +413,7 @@ func TestBackTest(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
-				store.EXPECT().GetBacktestValues(gomock.Any()).Times(0)
+				store.EXPECT().GetBacktestValues(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
@@ -256,7 +441,7 @@ func TestBackTest(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
-				store.EXPECT().GetBacktestValues(gomock.Any()).Times(0)
+				store.EXPECT().GetBacktestValues(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
@@ -284,7 +469,7 @@ func TestBackTest(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
-				store.EXPECT().GetBacktestValues(gomock.Any()).Times(0)
+				store.EXPECT().GetBacktestValues(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
@@ -312,7 +497,7 @@ func TestBackTest(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
-				store.EXPECT().GetBacktestValues(gomock.Any()).Times(0)
+				store.EXPECT().GetBacktestValues(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
@@ -340,7 +525,7 @@ func TestBackTest(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
-				store.EXPECT().GetBacktestValues(gomock.Any()).Times(1).Return(db.GetBacktestValuesResult{}, sql.ErrNoRows)
+				store.EXPECT().GetBacktestValues(gomock.Any(), gomock.Any()).Times(1).Return(db.GetBacktestValuesResult{}, sql.ErrNoRows)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusNotFound, recorder.Code)
@@ -368,7 +553,7 @@ func TestBackTest(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
-				store.EXPECT().GetBacktestValues(gomock.Any()).Times(1).Return(db.GetBacktestValuesResult{}, sql.ErrConnDone)
+				store.EXPECT().GetBacktestValues(gomock.Any(), gomock.Any()).Times(1).Return(db.GetBacktestValuesResult{}, sql.ErrConnDone)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
@@ -404,6 +589,22 @@ func TestBackTest(t *testing.T) {
 	}
 }
 
+func TestIssueDates(t *testing.T) {
+	dates := []string{"2023-01-30", "2023-01-31", "2023-02-01", "2023-02-06", "2023-02-07", "2023-03-01"}
+	testCases := []struct {
+		stride string
+		want   []string
+	}{
+		{stride: "", want: dates},
+		{stride: DailyStride, want: dates},
+		{stride: WeeklyStride, want: []string{"2023-01-30", "2023-02-06", "2023-03-01"}},
+		{stride: MonthlyStride, want: []string{"2023-01-30", "2023-02-01", "2023-03-01"}},
+	}
+	for _, tc := range testCases {
+		require.Equal(t, tc.want, issueDates(dates, tc.stride), tc.stride)
+	}
+}
+
 func TestMinMax(t *testing.T) {
 	type testCases struct {
 		name  string
@@ -532,3 +733,46 @@ func TestFCNPayout(t *testing.T) {
 		})
 	}
 }
+
+func TestSimulatedCashflows(t *testing.T) {
+	stocks := []string{"AAPL", "AVGO", "TSLA"}
+	arg := pricerRequest{
+		Stocks:        stocks,
+		Strike:        0.80,
+		Cpn:           0.50,
+		BarrierCpn:    0.50,
+		FixCpn:        0.50,
+		KO:            1.05,
+		KI:            0.70,
+		KC:            0.80,
+		Maturity:      12,
+		Freq:          3,
+		IsEuro:        true,
+		Calendars:     map[string]string{"TSLA": "XETRA"},
+		Convention:    calendar.ModifiedFollowing,
+		SettlementLag: 2,
+	}
+	fixings := map[string]float64{"AAPL": 130.03, "AVGO": 553.54, "TSLA": 109.1}
+	means := map[string]float64{"AAPL": -0.0024065238291240444, "AVGO": 0.0029074417269861117, "TSLA": -0.015126507431615293}
+	models := map[string]mc.Model{
+		"AAPL": mc.HypHyp{Sigma: 0.38956884573910466, Alpha: 0.31754204762725213, Beta: 0.09668058826922904, Kappa: 18.55196217354717, Rho: -0.08156231110497626},
+		"AVGO": mc.HypHyp{Sigma: 0.33169818989315536, Alpha: 0.414590139046433, Beta: 0.4096664715295601, Kappa: 31.15386811469867, Rho: -0.2467638237838846},
+		"TSLA": mc.HypHyp{Sigma: 0.926280232995074, Alpha: 0.09316279525141707, Beta: 0.11993430192118938, Kappa: 167.74229696983923, Rho: 0.9999999982622454},
+	}
+	corr := mat.NewSymDense(3, []float64{1.0, 0.5135700399870929, 0.5498852123024683, 0.5135700399870929, 1.0, 0.8289691320432666, 0.5498852123024683, 0.8289691320432666, 1.0})
+
+	start, _ := time.Parse(Layout, "2022-12-28")
+	dates, err := scheduleDates(stocks, arg, start)
+	require.NoError(t, err)
+	joint := calendar.Join("", calendar.NYSE, calendar.NYSE, calendar.XETRA)
+
+	cfs, err := simulatedCashflows("2022-12-28", stocks, arg, fixings, means, fixings, models, corr)
+	require.NoError(t, err)
+	require.NotEmpty(t, cfs)
+	for _, cf := range cfs {
+		require.True(t, util.IsIn(cf.Date, dates["cpndates"]), cf.Date)
+		if cf.Type != payoff.KnockIn && cf.Type != payoff.KnockOut {
+			require.Equal(t, joint.AddBusinessDays(cf.Date, 2), cf.PayDate)
+		}
+	}
+}
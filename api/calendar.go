@@ -0,0 +1,84 @@
+package api
+
+import (
+	"strings"
+	"time"
+
+	"github.com/banachtech/spotted-zebra/calendar"
+	"github.com/banachtech/spotted-zebra/payoff"
+	"github.com/banachtech/spotted-zebra/util"
+)
+
+const DefaultCalendar = calendar.DefaultName
+
+// Exchange calendar of each underlying. Stocks not listed trade on the default calendar.
+var StockCalendars = map[string]string{"AAPL": "NYSE", "AMZN": "NYSE", "META": "NYSE", "MSFT": "NYSE", "TSLA": "NYSE", "GOOG": "NYSE", "NVDA": "NYSE", "AVGO": "NYSE", "QCOM": "NYSE", "INTC": "NYSE"}
+
+// Return the exchange calendar of each stock, applying any overrides from the request.
+func stockCalendars(stocks []string, overrides map[string]string) ([]*calendar.Calendar, error) {
+	names := map[string]string{}
+	for _, v := range stocks {
+		names[v] = DefaultCalendar
+		if c, ok := StockCalendars[v]; ok {
+			names[v] = c
+		}
+	}
+	for k, v := range overrides {
+		if _, ok := names[strings.ToUpper(k)]; ok {
+			names[strings.ToUpper(k)] = v
+		}
+	}
+
+	var out []*calendar.Calendar
+	for _, v := range stocks {
+		c, err := calendar.Get(names[v])
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// Payment date of cashflows fixed on an observation date, the settlement lag in business days
+// later on the joint calendar of the stocks.
+func paymentDates(stocks []string, arg pricerRequest) (func(time.Time) time.Time, error) {
+	cals, err := stockCalendars(stocks, arg.Calendars)
+	if err != nil {
+		return nil, err
+	}
+	cal := calendar.Join("", cals...)
+	return func(d time.Time) time.Time {
+		return cal.AddBusinessDays(d, arg.SettlementLag)
+	}, nil
+}
+
+// Generate the observation dates of the requested note from start on the calendars of the stocks.
+func scheduleDates(stocks []string, arg pricerRequest, start time.Time) (map[string][]time.Time, error) {
+	cals, err := stockCalendars(stocks, arg.Calendars)
+	if err != nil {
+		return nil, err
+	}
+	rule := util.DateRule{Disruption: arg.Disruption, Convention: arg.Convention, EOM: arg.EOM}
+	return util.GenerateBasketDates(start, arg.Maturity, arg.Freq, arg.NonCall, cals, rule)
+}
+
+// Observe each stock of a product on its own fixing dates under individual disruption
+// postponement. Other products are returned unchanged.
+func postponed(stocks []string, arg pricerRequest, start time.Time, product payoff.Payoff, dates map[string][]time.Time) (payoff.Payoff, error) {
+	if arg.Disruption != util.IndividualPostponement {
+		return product, nil
+	}
+	cals, err := stockCalendars(stocks, arg.Calendars)
+	if err != nil {
+		return nil, err
+	}
+	rule := util.DateRule{Disruption: arg.Disruption, Convention: arg.Convention, EOM: arg.EOM}
+	fixdates := map[string][]time.Time{}
+	for i, v := range stocks {
+		if fixdates[v], err = util.ScheduleDates(start, arg.Maturity, arg.Freq, cals[i], rule); err != nil {
+			return nil, err
+		}
+	}
+	return payoff.NewPostponed(product, dates["cpndates"], fixdates)
+}
@@ -0,0 +1,23 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/banachtech/spotted-zebra/calendar"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStockCalendars(t *testing.T) {
+	stocks := []string{"AAPL", "TSLA"}
+
+	cals, err := stockCalendars(stocks, nil)
+	require.NoError(t, err)
+	require.Equal(t, []*calendar.Calendar{calendar.NYSE, calendar.NYSE}, cals)
+
+	cals, err = stockCalendars(stocks, map[string]string{"tsla": "hkex", "GOOG": "TSE"})
+	require.NoError(t, err)
+	require.Equal(t, []*calendar.Calendar{calendar.NYSE, calendar.HKEX}, cals)
+
+	_, err = stockCalendars(stocks, map[string]string{"TSLA": "LSE"})
+	require.Error(t, err)
+}
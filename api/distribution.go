@@ -0,0 +1,85 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/banachtech/spotted-zebra/payoff"
+	"gonum.org/v1/gonum/stat"
+)
+
+// Percentiles of the discounted path payout reported with the distribution
+var payoutPercentiles = []float64{0.01, 0.05, 0.50, 0.95}
+
+// Distribution of discounted path payouts
+type distributionResult struct {
+	Histogram              []histogramBin     `json:"histogram"`
+	Percentiles            map[string]float64 `json:"percentiles"`
+	CapitalLossProbability float64            `json:"capital_loss_probability"`
+}
+
+// Fraction of paths with a discounted payout in [Lower, Upper)
+type histogramBin struct {
+	Lower       float64 `json:"lower"`
+	Upper       float64 `json:"upper"`
+	Probability float64 `json:"probability"`
+}
+
+// Summarise the discounted payouts of simulated path cashflows in a histogram of equal width bins
+// between the smallest and largest payout. The note loses capital on paths returning less than
+// the notional in undiscounted principal, whether redeemed in cash or delivered in shares. An
+// accumulator, which has no principal, loses capital on paths settling a net loss.
+func payoutDistribution(cfs [][]payoff.Cashflow, t0 time.Time, bins int) distributionResult {
+	n := float64(len(cfs))
+	res := distributionResult{Histogram: make([]histogramBin, bins), Percentiles: map[string]float64{}}
+
+	x := make([]float64, len(cfs))
+	for i, v := range cfs {
+		x[i] = payoff.PV(v, t0)
+		if lostCapital(v) {
+			res.CapitalLossProbability += 1 / n
+		}
+	}
+	sort.Float64s(x)
+
+	lo, hi := x[0], x[len(x)-1]
+	width := (hi - lo) / float64(bins)
+	for i := range res.Histogram {
+		res.Histogram[i].Lower = lo + float64(i)*width
+		res.Histogram[i].Upper = lo + float64(i+1)*width
+	}
+	for _, v := range x {
+		i := bins - 1
+		if width > 0 {
+			i = int(math.Min(math.Floor((v-lo)/width), float64(bins-1)))
+		}
+		res.Histogram[i].Probability += 1 / n
+	}
+
+	for _, p := range payoutPercentiles {
+		res.Percentiles[fmt.Sprintf("%g", 100*p)] = stat.Quantile(p, stat.Empirical, x, nil)
+	}
+	return res
+}
+
+// Whether a path loses capital: less than the notional in undiscounted principal for a note, or
+// a net loss on the settlements of an accumulator
+func lostCapital(cfs []payoff.Cashflow) bool {
+	principal, settled := 0.0, 0.0
+	hasPrincipal := false
+	for _, cf := range cfs {
+		switch cf.Type {
+		case payoff.Redemption, payoff.Delivery, payoff.FractionalCash:
+			principal += cf.Amount
+			hasPrincipal = true
+		case payoff.Accumulation:
+			settled += cf.Amount
+		}
+	}
+	if hasPrincipal {
+		return principal < 1
+	}
+	return settled < 0
+}
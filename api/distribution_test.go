@@ -0,0 +1,93 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/banachtech/spotted-zebra/payoff"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayoutDistribution(t *testing.T) {
+	t0 := time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC)
+
+	// Undiscounted payouts of 0.0, 0.01, ..., 0.99, 1.0, ..., 1.99 paid on the valuation date
+	var cfs [][]payoff.Cashflow
+	for i := 0; i < 200; i++ {
+		cfs = append(cfs, []payoff.Cashflow{{Date: t0, Type: payoff.Redemption, Amount: float64(i) / 100}})
+	}
+
+	res := payoutDistribution(cfs, t0, 4)
+	require.Len(t, res.Histogram, 4)
+	total := 0.0
+	for _, v := range res.Histogram {
+		total += v.Probability
+	}
+	require.InDelta(t, 1.0, total, 1e-12)
+	require.InDelta(t, 0.0, res.Histogram[0].Lower, 1e-12)
+	require.InDelta(t, 1.99, res.Histogram[3].Upper, 1e-12)
+	require.InDelta(t, 0.25, res.Histogram[0].Probability, 1e-12)
+	require.InDelta(t, 0.5, res.CapitalLossProbability, 1e-12)
+	require.InDelta(t, 0.01, res.Percentiles["1"], 1e-12)
+	require.InDelta(t, 0.09, res.Percentiles["5"], 1e-12)
+	require.InDelta(t, 0.99, res.Percentiles["50"], 1e-12)
+	require.InDelta(t, 1.89, res.Percentiles["95"], 1e-12)
+}
+
+func TestCapitalLoss(t *testing.T) {
+	t0 := time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC)
+	t1 := t0.AddDate(1, 0, 0)
+
+	testCases := []struct {
+		name string
+		cfs  []payoff.Cashflow
+		loss bool
+	}{
+		{name: "PAR", cfs: []payoff.Cashflow{{Date: t1, Type: payoff.Redemption, Amount: 1.0}}},
+		{name: "BELOW_PAR", cfs: []payoff.Cashflow{{Date: t1, Type: payoff.Redemption, Amount: 0.9}}, loss: true},
+		{
+			name: "COUPON_NOT_PRINCIPAL",
+			cfs: []payoff.Cashflow{
+				{Date: t1, Type: payoff.FixedCoupon, Amount: 0.2},
+				{Date: t1, Type: payoff.Redemption, Amount: 0.9},
+			},
+			loss: true,
+		},
+		{
+			name: "PHYSICAL",
+			cfs: []payoff.Cashflow{
+				{Date: t1, Type: payoff.Delivery, Amount: 0.95, Ticker: "AAPL", Shares: 7},
+				{Date: t1, Type: payoff.FractionalCash, Amount: 0.05},
+			},
+		},
+		{
+			name: "ACCUMULATOR_GAIN",
+			cfs: []payoff.Cashflow{
+				{Date: t0.AddDate(0, 6, 0), Type: payoff.Accumulation, Amount: -0.02},
+				{Date: t1, Type: payoff.Accumulation, Amount: 0.05},
+			},
+		},
+		{
+			name: "ACCUMULATOR_LOSS",
+			cfs: []payoff.Cashflow{
+				{Date: t0.AddDate(0, 6, 0), Type: payoff.Accumulation, Amount: 0.02},
+				{Date: t1, Type: payoff.Accumulation, Amount: -0.05},
+				{Date: t1, Type: payoff.KnockOut},
+			},
+			loss: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var cfs [][]payoff.Cashflow
+			for i := 0; i < 100; i++ {
+				cfs = append(cfs, tc.cfs)
+			}
+			want := 0.0
+			if tc.loss {
+				want = 1.0
+			}
+			require.InDelta(t, want, payoutDistribution(cfs, t0, 4).CapitalLossProbability, 1e-12)
+		})
+	}
+}
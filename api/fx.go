@@ -0,0 +1,103 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/banachtech/spotted-zebra/mc"
+)
+
+const DefaultCurrency = "USD"
+
+// Quotation currency of each underlying. Stocks not listed are quoted in the default currency.
+var StockCurrencies = map[string]string{"AAPL": "USD", "AMZN": "USD", "META": "USD", "MSFT": "USD", "TSLA": "USD", "GOOG": "USD", "NVDA": "USD", "AVGO": "USD", "QCOM": "USD", "INTC": "USD"}
+
+// FX inputs for a foreign currency against the settlement currency. Rate is the number of units
+// of settlement currency per unit of foreign currency, and Corr is the correlation of each stock
+// quoted in the foreign currency with the FX rate.
+type fxRequest struct {
+	Rate float64            `json:"rate" binding:"required,gt=0"`
+	Vol  float64            `json:"vol" binding:"min=0"`
+	Corr map[string]float64 `json:"corr"`
+}
+
+// Return the quotation currency of each stock, applying any overrides from the request.
+func stockCurrencies(stocks []string, overrides map[string]string) map[string]string {
+	out := map[string]string{}
+	for _, v := range stocks {
+		out[v] = DefaultCurrency
+		if c, ok := StockCurrencies[v]; ok {
+			out[v] = c
+		}
+	}
+	for k, v := range overrides {
+		if _, ok := out[strings.ToUpper(k)]; ok {
+			out[strings.ToUpper(k)] = strings.ToUpper(v)
+		}
+	}
+	return out
+}
+
+// Construct the simulation basket. Stocks quoted in a currency other than the settlement
+// currency are simulated in their quanto measure, with drift adjustment rho * sigma_S * sigma_FX
+// using the stock volatility in vols.
+func newBasket(stocks []string, arg pricerRequest, models map[string]mc.Model, vols map[string]float64) (mc.Basket, error) {
+	settle := settlementCurrency(arg)
+	currencies := stockCurrencies(stocks, arg.Currencies)
+	quanto := map[string]float64{}
+	for _, v := range stocks {
+		if currencies[v] == settle {
+			continue
+		}
+		fx, ok := fxInput(arg.FX, currencies[v])
+		if !ok {
+			return nil, fmt.Errorf("missing fx inputs for %s", currencies[v])
+		}
+		quanto[v] = fxCorr(fx, v) * vols[v] * fx.Vol
+	}
+	return mc.NewBasket(models).SetQuanto(currencies, quanto), nil
+}
+
+// Convert stock fixings to the settlement currency at the given FX rates.
+func settlementFixings(stocks []string, arg pricerRequest, fixings map[string]float64) (map[string]float64, error) {
+	settle := settlementCurrency(arg)
+	currencies := stockCurrencies(stocks, arg.Currencies)
+	out := map[string]float64{}
+	for _, v := range stocks {
+		out[v] = fixings[v]
+		if currencies[v] == settle {
+			continue
+		}
+		fx, ok := fxInput(arg.FX, currencies[v])
+		if !ok {
+			return nil, fmt.Errorf("missing fx inputs for %s", currencies[v])
+		}
+		out[v] *= fx.Rate
+	}
+	return out, nil
+}
+
+func settlementCurrency(arg pricerRequest) string {
+	if arg.Currency == "" {
+		return DefaultCurrency
+	}
+	return strings.ToUpper(arg.Currency)
+}
+
+func fxInput(fx map[string]fxRequest, currency string) (fxRequest, bool) {
+	for k, v := range fx {
+		if strings.ToUpper(k) == currency {
+			return v, true
+		}
+	}
+	return fxRequest{}, false
+}
+
+func fxCorr(fx fxRequest, ticker string) float64 {
+	for k, v := range fx.Corr {
+		if strings.ToUpper(k) == ticker {
+			return v
+		}
+	}
+	return 0
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/banachtech/spotted-zebra/mc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBasket(t *testing.T) {
+	stocks := []string{"AAPL", "TSLA"}
+	models := map[string]mc.Model{
+		"AAPL": mc.HypHyp{Sigma: 0.38956884573910466, Alpha: 0.31754204762725213, Beta: 0.09668058826922904, Kappa: 18.55196217354717, Rho: -0.08156231110497626},
+		"TSLA": mc.HypHyp{Sigma: 0.926280232995074, Alpha: 0.09316279525141707, Beta: 0.11993430192118938, Kappa: 167.74229696983923, Rho: 0.9999999982622454},
+	}
+	vols := map[string]float64{"AAPL": 0.4, "TSLA": 0.9}
+	fixings := map[string]float64{"AAPL": 130.03, "TSLA": 109.1}
+
+	type testCases struct {
+		name    string
+		arg     pricerRequest
+		quanto  map[string]float64
+		fixings map[string]float64
+		isErr   bool
+	}
+
+	for _, test := range []testCases{
+		{
+			name:    "DOMESTIC",
+			arg:     pricerRequest{},
+			quanto:  map[string]float64{"AAPL": 0, "TSLA": 0},
+			fixings: fixings,
+		},
+		{
+			name: "QUANTO",
+			arg: pricerRequest{
+				Currency:   "usd",
+				Currencies: map[string]string{"tsla": "hkd"},
+				FX:         map[string]fxRequest{"HKD": {Rate: 0.128, Vol: 0.05, Corr: map[string]float64{"TSLA": 0.5}}},
+			},
+			quanto:  map[string]float64{"AAPL": 0, "TSLA": 0.5 * 0.9 * 0.05},
+			fixings: map[string]float64{"AAPL": 130.03, "TSLA": 109.1 * 0.128},
+		},
+		{
+			name: "MISSING_FX",
+			arg: pricerRequest{
+				Currency: "EUR",
+			},
+			isErr: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			bsk, err := newBasket(stocks, test.arg, models, vols)
+			settleFixings, err2 := settlementFixings(stocks, test.arg, fixings)
+			if test.isErr {
+				require.Error(t, err)
+				require.Error(t, err2)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, err2)
+			for _, v := range bsk {
+				require.InDelta(t, test.quanto[v.Ticker], v.Quanto, 1e-12)
+				require.InDelta(t, test.fixings[v.Ticker], settleFixings[v.Ticker], 1e-9)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/banachtech/spotted-zebra/calendar"
+	"github.com/gin-gonic/gin"
+)
+
+var calendarName = regexp.MustCompile(`^[A-Za-z0-9_]{1,32}$`)
+
+type holidayCalendarRequest struct {
+	Holidays []string `json:"holidays" binding:"required,min=1"`
+}
+
+type businessDaysRequest struct {
+	Start string `form:"start" binding:"required"`
+	End   string `form:"end" binding:"required"`
+}
+
+type businessDaysResult struct {
+	Calendar string   `json:"calendar"`
+	Count    int      `json:"count"`
+	Dates    []string `json:"dates"`
+}
+
+func (server *Server) listCalendars(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"calendars": calendar.Names()})
+}
+
+// Replace the holidays of a calendar with a JSON list of dates or an iCalendar file. The
+// calendar is saved to the db and used by the pricer from the next request.
+func (server *Server) uploadCalendar(c *gin.Context) {
+	name := c.Param("name")
+	if !calendarName.MatchString(name) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(errors.New("invalid calendar name")))
+		return
+	}
+
+	var cal *calendar.Calendar
+	var err error
+	if c.ContentType() == "text/calendar" {
+		cal, err = calendar.ParseICS(name, c.Request.Body)
+	} else {
+		var req holidayCalendarRequest
+		if err = c.ShouldBindJSON(&req); err == nil {
+			cal, err = calendar.FromDates(name, req.Holidays)
+		}
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if len(cal.Dates) == 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(errors.New("calendar has no holidays")))
+		return
+	}
+
+	dates := make([]string, len(cal.Dates))
+	for i, v := range cal.Dates {
+		dates[i] = v.Format(calendar.DateLayout)
+	}
+	if err := server.store.SaveHolidays(c, cal.Name, dates); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	calendar.Register(cal)
+
+	c.JSON(http.StatusOK, gin.H{"calendar": cal.Name, "holidays": len(dates)})
+}
+
+// Longest range of business days listed in one request
+const maxBusinessDaysYears = 10
+
+// List the business days of a calendar between two dates, both included, at most
+// maxBusinessDaysYears apart within the precomputed years of the calendars.
+func (server *Server) businessDays(c *gin.Context) {
+	var req businessDaysRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	cal, err := calendar.Get(c.Param("name"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, errorResponse(err))
+		return
+	}
+	start, err := time.Parse(calendar.DateLayout, req.Start)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	end, err := time.Parse(calendar.DateLayout, req.End)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if start.Year() < calendar.IndexFrom || end.Year() > calendar.IndexTo || end.After(start.AddDate(maxBusinessDaysYears, 0, 0)) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest, "msg": fmt.Sprintf("Business days can be listed over at most %d years between %d and %d", maxBusinessDaysYears, calendar.IndexFrom, calendar.IndexTo)})
+		return
+	}
+	days, err := cal.BusinessDates(start, end)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if !cal.IsBusinessDay(start) {
+		days = days[1:]
+	}
+
+	res := businessDaysResult{Calendar: cal.Name, Count: len(days), Dates: make([]string, len(days))}
+	for i, v := range days {
+		res.Dates[i] = v.Format(calendar.DateLayout)
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// LoadCalendars registers the holiday calendars read from files in dir, if given, and then
+// those saved in the db, which replace built-in and file calendars of the same name.
+func (server *Server) LoadCalendars(ctx context.Context, dir string) error {
+	if dir != "" {
+		cals, err := calendar.LoadDir(dir)
+		if err != nil {
+			return err
+		}
+		for _, v := range cals {
+			calendar.Register(v)
+		}
+	}
+
+	rows, err := server.store.ListHolidays(ctx)
+	if err != nil {
+		return err
+	}
+	dates := map[string][]string{}
+	var names []string
+	for _, v := range rows {
+		if _, ok := dates[v.Calendar]; !ok {
+			names = append(names, v.Calendar)
+		}
+		dates[v.Calendar] = append(dates[v.Calendar], v.Date)
+	}
+	for _, v := range names {
+		cal, err := calendar.FromDates(v, dates[v])
+		if err != nil {
+			return err
+		}
+		calendar.Register(cal)
+	}
+	return nil
+}
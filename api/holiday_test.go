@@ -0,0 +1,264 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/banachtech/spotted-zebra/calendar"
+	mockdb "github.com/banachtech/spotted-zebra/db/mock"
+	db "github.com/banachtech/spotted-zebra/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalendars(t *testing.T) {
+	prefix := "dmag_d8K"
+	value := db.User{
+		EmailAddress: "test123@example.com",
+		Prefix:       "dmag_d8K",
+		Token:        "$2a$14$eIWUgPMqNQbpPveJdoQ8sOSw7DY5zBXUP3uUhm31LrfbArv6ZIhXe",
+		GeneratedAt:  time.Now().Format(Layout2),
+		ExpiredAt:    time.Now().AddDate(1, 0, 0).Format(Layout2),
+	}
+	testCases := []struct {
+		name          string
+		method        string
+		url           string
+		contentType   string
+		body          string
+		admin         bool
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recoder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "LIST",
+			method: http.MethodGet,
+			url:    "/v1/calendars",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				var res map[string][]string
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Contains(t, res["calendars"], "NYSE")
+			},
+		},
+		{
+			name:        "UPLOAD_JSON",
+			admin:       true,
+			method:      http.MethodPut,
+			url:         "/v1/calendars/test_lse",
+			contentType: "application/json",
+			body:        `{"holidays": ["2024-01-01", "2024-08-26"]}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
+				store.EXPECT().SaveHolidays(gomock.Any(), gomock.Eq("TEST_LSE"), gomock.Eq([]string{"2024-01-01", "2024-08-26"})).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				c, err := calendar.Get("TEST_LSE")
+				require.NoError(t, err)
+				require.Len(t, c.Dates, 2)
+			},
+		},
+		{
+			name:        "UPLOAD_ICS",
+			admin:       true,
+			method:      http.MethodPut,
+			url:         "/v1/calendars/TEST_ASX",
+			contentType: "text/calendar",
+			body:        "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20240126\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
+				store.EXPECT().SaveHolidays(gomock.Any(), gomock.Eq("TEST_ASX"), gomock.Eq([]string{"2024-01-26"})).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:        "INVALID_HOLIDAY",
+			admin:       true,
+			method:      http.MethodPut,
+			url:         "/v1/calendars/TEST_LSE",
+			contentType: "application/json",
+			body:        `{"holidays": ["2024-01-32"]}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
+				store.EXPECT().SaveHolidays(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "INVALID_NAME",
+			admin:       true,
+			method:      http.MethodPut,
+			url:         "/v1/calendars/TEST-LSE",
+			contentType: "application/json",
+			body:        `{"holidays": ["2024-01-01"]}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
+				store.EXPECT().SaveHolidays(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "INTERNAL_SERVER_ERROR",
+			admin:       true,
+			method:      http.MethodPut,
+			url:         "/v1/calendars/TEST_FAILED",
+			contentType: "application/json",
+			body:        `{"holidays": ["2024-01-01"]}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
+				store.EXPECT().SaveHolidays(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				_, err := calendar.Get("TEST_FAILED")
+				require.Error(t, err)
+			},
+		},
+		{
+			name:        "NOT_ADMIN",
+			method:      http.MethodPut,
+			url:         "/v1/calendars/NYSE",
+			contentType: "application/json",
+			body:        `{"holidays": ["2024-01-01"]}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
+				store.EXPECT().SaveHolidays(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				c, err := calendar.Get("NYSE")
+				require.NoError(t, err)
+				require.Same(t, calendar.NYSE, c)
+			},
+		},
+		{
+			name:   "BUSINESS_DAYS",
+			method: http.MethodGet,
+			url:    "/v1/calendars/nyse/business_days?start=2024-03-24&end=2024-04-02",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				var res businessDaysResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, businessDaysResult{
+					Calendar: "NYSE",
+					Count:    6,
+					Dates:    []string{"2024-03-25", "2024-03-26", "2024-03-27", "2024-03-28", "2024-04-01", "2024-04-02"},
+				}, res)
+			},
+		},
+		{
+			name:   "UNKNOWN_CALENDAR",
+			method: http.MethodGet,
+			url:    "/v1/calendars/LSE_UNKNOWN/business_days?start=2024-03-24&end=2024-04-02",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "RANGE_TOO_LONG",
+			method: http.MethodGet,
+			url:    "/v1/calendars/NYSE/business_days?start=2000-01-01&end=2010-01-02",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "OUTSIDE_INDEX",
+			method: http.MethodGet,
+			url:    "/v1/calendars/NYSE/business_days?start=1000-01-01&end=1000-01-31",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "INVALID_RANGE",
+			method: http.MethodGet,
+			url:    "/v1/calendars/NYSE/business_days?start=2024-04-02&end=2024-03-24",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewServer(store)
+			if tc.admin {
+				server.SetAdmins([]string{prefix})
+			}
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(tc.method, tc.url, strings.NewReader(tc.body))
+			require.NoError(t, err)
+			if tc.contentType != "" {
+				request.Header.Set("Content-Type", tc.contentType)
+			}
+
+			authorizationHeader := fmt.Sprintf("%s %s", authorizationTypeBearer, "dmag_d8K.RGbV3hb3LEwYohYW")
+			request.Header.Set(authorizationHeaderKey, authorizationHeader)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestLoadCalendars(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListHolidays(gomock.Any()).Times(1).Return([]db.Holiday{
+		{Calendar: "TEST_DB", Date: "2024-01-02"},
+		{Calendar: "TEST_DB", Date: "2024-01-03"},
+	}, nil)
+
+	server := NewServer(store)
+	require.NoError(t, server.LoadCalendars(context.Background(), ""))
+
+	c, err := calendar.Get("TEST_DB")
+	require.NoError(t, err)
+	require.Len(t, c.Dates, 2)
+
+	store.EXPECT().ListHolidays(gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+	require.Error(t, server.LoadCalendars(context.Background(), ""))
+}
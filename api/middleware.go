@@ -73,3 +73,12 @@ func (server *Server) authentication(c *gin.Context) {
 	c.Set("prefix", prefix)
 	c.Next()
 }
+
+// Reject requests from API keys that are not admins. Must run after authentication.
+func (server *Server) adminOnly(c *gin.Context) {
+	if !server.admins[c.GetString("prefix")] {
+		c.AbortWithStatusJSON(http.StatusForbidden, errorResponse(errors.New("api key is not allowed to change shared data")))
+		return
+	}
+	c.Next()
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	mockdb "github.com/banachtech/spotted-zebra/db/mock"
 	db "github.com/banachtech/spotted-zebra/db/sqlc"
@@ -20,8 +21,8 @@ func TestAuthMiddleware(t *testing.T) {
 		EmailAddress: "test123@example.com",
 		Prefix:       "dmag_d8K",
 		Token:        "$2a$14$eIWUgPMqNQbpPveJdoQ8sOSw7DY5zBXUP3uUhm31LrfbArv6ZIhXe",
-		GeneratedAt:  "2022-12-30 18:09:35",
-		ExpiredAt:    "2023-06-30 18:09:35",
+		GeneratedAt:  time.Now().Format(Layout2),
+		ExpiredAt:    time.Now().AddDate(1, 0, 0).Format(Layout2),
 	}
 	value2 := db.User{
 		EmailAddress: "test123@example.com",
@@ -7,6 +7,7 @@ import (
 	"math"
 	"net/http"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -23,21 +24,68 @@ import (
 )
 
 type pricerRequest struct {
-	Stocks     []string `json:"stocks" binding:"required"`
-	Strike     float64  `json:"strike" binding:"required"`
-	Cpn        float64  `json:"autocall_coupon_rate"`
-	BarrierCpn float64  `json:"barrier_coupon_rate"`
-	FixCpn     float64  `json:"fixed_coupon_rate"`
-	KO         float64  `json:"knock_out_barrier"`
-	KI         float64  `json:"knock_in_barrier"`
-	KC         float64  `json:"coupon_barrier"`
-	Maturity   int      `json:"maturity" binding:"required,min=1"`
-	Freq       int      `json:"frequency" binding:"required,min=1"`
-	IsEuro     bool     `json:"isEuro"`
+	ProductType    string               `json:"product_type" binding:"omitempty,oneof=fcn phoenix reverse_convertible eln snowball accumulator decumulator"`
+	Stocks         []string             `json:"stocks" binding:"required"`
+	BasketType     string               `json:"basket_type" binding:"omitempty,oneof=worst_of best_of average weighted ranked"`
+	Weights        map[string]float64   `json:"weights"`
+	Rank           int                  `json:"rank" binding:"min=0"`
+	Strike         float64              `json:"strike" binding:"required"`
+	Cpn            float64              `json:"autocall_coupon_rate"`
+	BarrierCpn     float64              `json:"barrier_coupon_rate"`
+	FixCpn         float64              `json:"fixed_coupon_rate"`
+	KO             float64              `json:"knock_out_barrier"`
+	KI             float64              `json:"knock_in_barrier"`
+	KC             float64              `json:"coupon_barrier"`
+	KOSchedule     []float64            `json:"knock_out_schedule"`
+	KCSchedule     []float64            `json:"coupon_barrier_schedule"`
+	Maturity       int                  `json:"maturity" binding:"required,min=1"`
+	Freq           int                  `json:"frequency" binding:"required,min=1"`
+	NonCall        int                  `json:"non_call_periods" binding:"min=0"`
+	IsEuro         bool                 `json:"isEuro"`
+	KIMonitoring   string               `json:"ki_monitoring" binding:"omitempty,oneof=daily continuous european"`
+	Settlement     string               `json:"settlement" binding:"omitempty,oneof=cash physical"`
+	Denomination   float64              `json:"denomination" binding:"min=0"`
+	Memory         bool                 `json:"memory"`
+	Gearing        float64              `json:"gearing" binding:"min=0"`
+	Currency       string               `json:"currency" binding:"omitempty,len=3"`
+	Currencies     map[string]string    `json:"stock_currencies"`
+	FX             map[string]fxRequest `json:"fx" binding:"dive"`
+	Bins           int                  `json:"histogram_bins" binding:"min=0,max=1000"`
+	ValuationDate  string               `json:"valuation_date"`
+	StrikeDate     string               `json:"strike_date"`
+	InitialFixings map[string]float64   `json:"initial_fixings"`
+	History        historyRequest       `json:"history"`
+	Calendars      map[string]string    `json:"stock_calendars"`
+	Disruption     string               `json:"disruption" binding:"omitempty,oneof=common individual"`
+	Convention     string               `json:"business_day_convention" binding:"omitempty,oneof=following modified_following preceding modified_preceding unadjusted"`
+	EOM            bool                 `json:"end_of_month"`
+	SettlementLag  int                  `json:"settlement_lag" binding:"min=0,max=30"`
+}
+
+// Observed history of a note struck before the valuation date
+type historyRequest struct {
+	KnockedIn     bool    `json:"knocked_in"`
+	MissedCoupons float64 `json:"missed_coupons" binding:"min=0"`
 }
 
 const Layout = "2006-01-02"
 
+// Summary of a monte-carlo valuation
+type pricerResult struct {
+	Price        float64             `json:"price"`
+	Schedule     scheduleResult      `json:"schedule"`
+	Distribution *distributionResult `json:"distribution,omitempty"`
+	Delivery     map[string]float64  `json:"delivery_probability,omitempty"`
+	MarketData   marketDataDates     `json:"market_data_dates"`
+}
+
+// Dates of the market data used for a valuation
+type marketDataDates struct {
+	Params string `json:"params"`
+	Stats  string `json:"stats"`
+	Corr   string `json:"correlations"`
+}
+
 var Pricerlimiters = make(map[string]*rate.Limiter)
 
 func getPricerLimiter(userID string) *rate.Limiter {
@@ -88,7 +136,17 @@ func (server *Server) pricer(c *gin.Context) {
 	}
 	req.Stocks = filterStocks
 
-	result, err := server.store.GetValues(c)
+	var result db.GetValuesResult
+	if req.ValuationDate != "" {
+		valDate, err := time.Parse(Layout, req.ValuationDate)
+		if err != nil || valDate.After(time.Now()) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest, "msg": fmt.Sprintf("Invalid valuation date: %s", req.ValuationDate)})
+			return
+		}
+		result, err = server.store.GetValuesAsOf(c, req.ValuationDate)
+	} else {
+		result, err = server.store.GetValues(c)
+	}
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.AbortWithStatusJSON(http.StatusNotFound, errorResponse(err))
@@ -101,13 +159,14 @@ func (server *Server) pricer(c *gin.Context) {
 
 	models, fixings, means, px, corrMatrix := constructor(result, filterStocks)
 
-	p, err := fcnPricer(filterStocks, req, fixings, means, px, models, corrMatrix)
+	res, err := productPricer(filterStocks, req, fixings, means, px, models, corrMatrix)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest, "msg": fmt.Sprintf("Failed compute FCN price: %s", err)})
 		return
 	}
+	res.MarketData = marketDataDates{Params: result.ParamDate, Stats: result.StatsDate, Corr: result.CorrDate}
 
-	c.JSON(http.StatusOK, gin.H{"price": p})
+	c.JSON(http.StatusOK, res)
 }
 
 func constructor(target db.GetValuesResult, filterStocks []string) (map[string]mc.Model, map[string]float64, map[string]float64, map[string]float64, *mat.SymDense) {
@@ -169,6 +228,292 @@ func constructor(target db.GetValuesResult, filterStocks []string) (map[string]m
 }
 
 func fcnPricer(stocks []string, arg pricerRequest, fixings, means, px map[string]float64, models map[string]mc.Model, corrMatrix *mat.SymDense) (float64, error) {
+	res, err := productPricer(stocks, arg, fixings, means, px, models, corrMatrix)
+	if err != nil {
+		return math.NaN(), err
+	}
+	return res.Price, nil
+}
+
+// Price the requested product and summarise the simulated cashflows.
+func productPricer(stocks []string, arg pricerRequest, fixings, means, px map[string]float64, models map[string]mc.Model, corrMatrix *mat.SymDense) (pricerResult, error) {
+	tNow, err := valuationDate(arg.ValuationDate)
+	if err != nil {
+		return pricerResult{}, err
+	}
+	start := tNow
+	if arg.StrikeDate != "" {
+		start, err = time.Parse(Layout, arg.StrikeDate)
+		if err != nil {
+			return pricerResult{}, err
+		}
+		if !start.Before(tNow) {
+			return pricerResult{}, errors.New("strike date must be before the valuation date")
+		}
+		fixings, err = initialFixings(stocks, arg.InitialFixings)
+		if err != nil {
+			return pricerResult{}, err
+		}
+	}
+	dates, err := scheduleDates(stocks, arg, start)
+	if err != nil {
+		return pricerResult{}, err
+	}
+
+	vols := atmVols(models, float64(arg.Maturity)/12.0)
+	bsk, err := newBasket(stocks, arg, models, vols)
+	if err != nil {
+		return pricerResult{}, err
+	}
+
+	settleFixings, err := settlementFixings(stocks, arg, fixings)
+	if err != nil {
+		return pricerResult{}, err
+	}
+
+	product, err := newPayoff(stocks, arg, settleFixings, vols, dates)
+	if err != nil {
+		return pricerResult{}, err
+	}
+	if arg.StrikeDate != "" {
+		if err := setHistory(product, tNow, arg.History); err != nil {
+			return pricerResult{}, err
+		}
+	}
+
+	observed, err := postponed(stocks, arg, start, product, dates)
+	if err != nil {
+		return pricerResult{}, err
+	}
+	pay, err := paymentDates(stocks, arg)
+	if err != nil {
+		return pricerResult{}, err
+	}
+	settled := payoff.NewSettled(observed, pay)
+
+	cfs, err := mcCashflows(stocks, settled, bsk, fixings, means, px, corrMatrix)
+	if err != nil {
+		return pricerResult{}, err
+	}
+
+	obsdates := product.Dates()
+	var cpndates []time.Time
+	for _, v := range dates["cpndates"] {
+		if v.After(obsdates[0]) {
+			cpndates = append(cpndates, v)
+		}
+	}
+	res := pricerResult{
+		Price:    mcPrice(cfs, obsdates[0]),
+		Schedule: cashflowSchedule(cfs, cpndates, settled.PayDate, obsdates[0], obsdates[len(obsdates)-1]),
+	}
+	if arg.Bins > 0 {
+		dist := payoutDistribution(cfs, obsdates[0], arg.Bins)
+		res.Distribution = &dist
+	}
+	if arg.Settlement == payoff.Physical {
+		res.Delivery = deliveryProbability(stocks, cfs)
+	}
+	return res, nil
+}
+
+// Valuation date of a request, today if none is given.
+func valuationDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Parse(Layout, time.Now().Format(Layout))
+	}
+	return time.Parse(Layout, s)
+}
+
+// Initial fixings of a note struck before the valuation date.
+func initialFixings(stocks []string, fixings map[string]float64) (map[string]float64, error) {
+	out := map[string]float64{}
+	for k, v := range fixings {
+		out[strings.ToUpper(k)] = v
+	}
+	for _, v := range stocks {
+		if out[v] <= 0 {
+			return nil, fmt.Errorf("missing initial fixing for %s", v)
+		}
+	}
+	return out, nil
+}
+
+// Set the observed history of a note valued during its life. Only the remaining observation
+// dates from the valuation date are simulated.
+func setHistory(product payoff.Payoff, valDate time.Time, h historyRequest) error {
+	p, ok := product.(interface {
+		SetHistory(time.Time, payoff.History) error
+	})
+	if !ok {
+		return errors.New("mid-life valuation is not supported for this product")
+	}
+	return p.SetHistory(valDate, payoff.History{KnockedIn: h.KnockedIn, MissedCoupons: h.MissedCoupons})
+}
+
+// Mean discounted value of simulated path cashflows.
+func mcPrice(cfs [][]payoff.Cashflow, t0 time.Time) float64 {
+	out := 0.0
+	for _, v := range cfs {
+		out += payoff.PV(v, t0)
+	}
+	return out / float64(len(cfs))
+}
+
+// Model implied at-the-money volatility of each stock for a maturity of T years.
+func atmVols(models map[string]mc.Model, T float64) map[string]float64 {
+	out := map[string]float64{}
+	for k, v := range models {
+		out[k] = v.IVol(1.0, T)
+	}
+	return out
+}
+
+// Probability of each ticker being delivered on knock-in, and of no delivery.
+func deliveryProbability(stocks []string, cfs [][]payoff.Cashflow) map[string]float64 {
+	out := map[string]float64{"none": 0}
+	for _, v := range stocks {
+		out[v] = 0
+	}
+	n := float64(len(cfs))
+	for _, path := range cfs {
+		delivered := false
+		for _, cf := range path {
+			if cf.Type == payoff.Delivery {
+				out[cf.Ticker] += 1 / n
+				delivered = true
+			}
+		}
+		if !delivered {
+			out["none"] += 1 / n
+		}
+	}
+	return out
+}
+
+// Request fields that each product type does not use, by JSON name
+var unusedFields = map[string][]string{
+	payoff.ProductFCN:                {"memory", "gearing"},
+	payoff.ProductPhoenix:            {"autocall_coupon_rate", "fixed_coupon_rate", "gearing"},
+	payoff.ProductReverseConvertible: {"autocall_coupon_rate", "barrier_coupon_rate", "knock_out_barrier", "coupon_barrier", "knock_out_schedule", "coupon_barrier_schedule", "non_call_periods", "memory", "gearing"},
+	payoff.ProductELN:                {"autocall_coupon_rate", "barrier_coupon_rate", "fixed_coupon_rate", "knock_out_barrier", "knock_in_barrier", "coupon_barrier", "knock_out_schedule", "coupon_barrier_schedule", "non_call_periods", "isEuro", "ki_monitoring", "denomination", "memory", "gearing"},
+	payoff.ProductSnowball:           {"barrier_coupon_rate", "fixed_coupon_rate", "coupon_barrier", "coupon_barrier_schedule", "isEuro", "ki_monitoring", "denomination", "memory", "gearing"},
+	payoff.ProductAccumulator:        {"autocall_coupon_rate", "barrier_coupon_rate", "fixed_coupon_rate", "knock_in_barrier", "coupon_barrier", "knock_out_schedule", "coupon_barrier_schedule", "non_call_periods", "isEuro", "ki_monitoring", "denomination", "memory"},
+	payoff.ProductDecumulator:        {"autocall_coupon_rate", "barrier_coupon_rate", "fixed_coupon_rate", "knock_in_barrier", "coupon_barrier", "knock_out_schedule", "coupon_barrier_schedule", "non_call_periods", "isEuro", "ki_monitoring", "denomination", "memory"},
+}
+
+// Reject product fields set in the request that the requested product type does not use
+func checkProductFields(arg pricerRequest) error {
+	product := arg.ProductType
+	if product == "" {
+		product = payoff.ProductFCN
+	}
+	set := map[string]bool{
+		"autocall_coupon_rate":    arg.Cpn != 0,
+		"barrier_coupon_rate":     arg.BarrierCpn != 0,
+		"fixed_coupon_rate":       arg.FixCpn != 0,
+		"knock_out_barrier":       arg.KO != 0,
+		"knock_in_barrier":        arg.KI != 0,
+		"coupon_barrier":          arg.KC != 0,
+		"knock_out_schedule":      len(arg.KOSchedule) > 0,
+		"coupon_barrier_schedule": len(arg.KCSchedule) > 0,
+		"non_call_periods":        arg.NonCall != 0,
+		"isEuro":                  arg.IsEuro,
+		"ki_monitoring":           arg.KIMonitoring != "",
+		"denomination":            arg.Denomination != 0,
+		"memory":                  arg.Memory,
+		"gearing":                 arg.Gearing != 0,
+	}
+	for _, v := range unusedFields[product] {
+		if set[v] {
+			return fmt.Errorf("%s is not used by %s", v, product)
+		}
+	}
+	return nil
+}
+
+// Construct the payoff selected by the request product type on the generated observation dates.
+func newPayoff(stocks []string, arg pricerRequest, fixings, vols map[string]float64, dates map[string][]time.Time) (payoff.Payoff, error) {
+	if err := checkProductFields(arg); err != nil {
+		return nil, err
+	}
+	basket, err := payoff.NewAggregation(arg.BasketType, arg.Weights, arg.Rank, stocks)
+	if err != nil {
+		return nil, err
+	}
+	configure := func(f *payoff.FCN) error {
+		if arg.KIMonitoring != "" {
+			if err := f.SetKIMonitoring(arg.KIMonitoring, vols); err != nil {
+				return err
+			}
+		}
+		if arg.Settlement != payoff.Physical {
+			return nil
+		}
+		return f.SetPhysicalSettlement(fixings, arg.Denomination)
+	}
+
+	switch arg.ProductType {
+	case payoff.ProductELN, payoff.ProductSnowball, payoff.ProductAccumulator, payoff.ProductDecumulator:
+		if arg.Settlement == payoff.Physical {
+			return nil, fmt.Errorf("physical settlement is not supported for %s", arg.ProductType)
+		}
+	}
+
+	switch arg.ProductType {
+	case "", payoff.ProductFCN:
+		fcn := payoff.NewFCN(stocks, arg.Strike, arg.Cpn, arg.BarrierCpn, arg.FixCpn, arg.KO, arg.KI, arg.KC, arg.Maturity, arg.Freq, arg.IsEuro, dates)
+		fcn.Basket = basket
+		if err := fcn.SetBarrierSchedule(arg.KOSchedule, arg.KCSchedule); err != nil {
+			return nil, err
+		}
+		if err := configure(fcn); err != nil {
+			return nil, err
+		}
+		return fcn, nil
+	case payoff.ProductPhoenix:
+		phoenix := payoff.NewPhoenix(stocks, arg.Strike, arg.BarrierCpn, arg.KO, arg.KI, arg.KC, arg.Maturity, arg.Freq, arg.IsEuro, arg.Memory, dates)
+		phoenix.Basket = basket
+		if err := phoenix.SetBarrierSchedule(arg.KOSchedule, arg.KCSchedule); err != nil {
+			return nil, err
+		}
+		if err := configure(&phoenix.FCN); err != nil {
+			return nil, err
+		}
+		return phoenix, nil
+	case payoff.ProductReverseConvertible:
+		rc := payoff.NewReverseConvertible(stocks, arg.Strike, arg.FixCpn, arg.KI, arg.Maturity, arg.Freq, arg.IsEuro, dates)
+		rc.Basket = basket
+		if arg.KI == 0 {
+			// A plain put is always observed at maturity
+			arg.KIMonitoring = ""
+		}
+		if err := configure(&rc.FCN); err != nil {
+			return nil, err
+		}
+		return rc, nil
+	case payoff.ProductELN:
+		eln := payoff.NewELN(stocks, arg.Strike, arg.Maturity, dates)
+		eln.Basket = basket
+		return eln, nil
+	case payoff.ProductSnowball:
+		snowball := payoff.NewSnowball(stocks, arg.Strike, arg.Cpn, arg.KO, arg.KI, arg.Maturity, arg.Freq, dates)
+		snowball.Basket = basket
+		if err := snowball.SetBarrierSchedule(arg.KOSchedule, nil); err != nil {
+			return nil, err
+		}
+		return snowball, nil
+	case payoff.ProductAccumulator, payoff.ProductDecumulator:
+		acc := payoff.NewAccumulator(stocks, arg.Strike, arg.KO, arg.Gearing, arg.Maturity, arg.Freq, arg.ProductType == payoff.ProductDecumulator, dates)
+		acc.Basket = basket
+		return acc, nil
+	default:
+		return nil, fmt.Errorf("unsupported product type: %s", arg.ProductType)
+	}
+}
+
+// Simulate the cashflows of a payoff over monte-carlo paths of the basket.
+func mcCashflows(stocks []string, product payoff.Payoff, bsk mc.Basket, fixings, means, px map[string]float64, corrMatrix *mat.SymDense) ([][]payoff.Cashflow, error) {
 	var wg sync.WaitGroup
 	pxRatio := map[string]float64{}
 	var mu []float64
@@ -177,21 +522,14 @@ func fcnPricer(stocks []string, arg pricerRequest, fixings, means, px map[string
 		mu = append(mu, means[v])
 	}
 
-	bsk := mc.NewBasket(models)
-
 	dz1, dz2, err := distributions(mu, corrMatrix)
 	if err != nil {
-		return math.NaN(), err
-	}
-
-	tNow, _ := time.Parse(Layout, time.Now().Format(Layout))
-	dates, err := util.GenerateDates(tNow, arg.Maturity, arg.Freq)
-	if err != nil {
-		return math.NaN(), err
+		return nil, err
 	}
 
+	obsdates := product.Dates()
 	nsamples := 10000
-	n_sims := len(dates["mcdates"]) - 1
+	n_sims := len(obsdates) - 1
 	z1 := map[int]map[string][]float64{}
 	z2 := map[int]map[string][]float64{}
 
@@ -212,33 +550,20 @@ func fcnPricer(stocks []string, arg pricerRequest, fixings, means, px map[string
 		}
 	}
 
-	fcn := payoff.NewFCN(stocks, arg.Strike, arg.Cpn, arg.BarrierCpn, arg.FixCpn, arg.KO, arg.KI, arg.KC, arg.Maturity, arg.Freq, arg.IsEuro, dates)
-
-	out := 0.0
-	ch := make(chan float64, nsamples)
-	// defer close(ch)
+	out := make([][]payoff.Cashflow, nsamples)
 
 	// Compute path payouts concurrently
 	for l := 0; l < nsamples; l++ {
 		wg.Add(1)
 		go func(l int) {
 			defer wg.Done()
-			path := bsk.Path(stocks, dates["mcdates"], pxRatio, z1[l], z2[l])
-			wop := wop(fixings, dates, path)
-			x := fcn.Payout(wop)
-			ch <- x
+			path := bsk.Path(stocks, obsdates, pxRatio, z1[l], z2[l])
+			out[l] = product.Cashflows(path)
 		}(l)
 	}
 
 	wg.Wait()
-	close(ch)
-
-	for l := 0; l < nsamples; l++ {
-		out += <-ch
-	}
-
-	price := out / float64(nsamples)
-	return price, nil
+	return out, nil
 }
 
 func distributions(sampleMu []float64, sampleCorr *mat.SymDense) (*distmv.Normal, distuv.Normal, error) {
@@ -251,19 +576,3 @@ func distributions(sampleMu []float64, sampleCorr *mat.SymDense) (*distmv.Normal
 
 	return dz1, dz2, nil
 }
-
-func wop(px map[string]float64, dates map[string][]time.Time, path mc.MCPath) []float64 {
-	// get the worst of performance array
-	var wop []float64
-	p := make([]float64, len(px))
-	for i := range dates["mcdates"] {
-		j := 0
-		for k := range px {
-			p[j] = path[k][i]
-			j++
-		}
-		minP := util.MinSlice(p)
-		wop = append(wop, minP)
-	}
-	return wop
-}
@@ -10,10 +10,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	mockdb "github.com/banachtech/spotted-zebra/db/mock"
 	db "github.com/banachtech/spotted-zebra/db/sqlc"
 	"github.com/banachtech/spotted-zebra/mc"
+	"github.com/banachtech/spotted-zebra/payoff"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
@@ -111,8 +113,8 @@ func TestPricer(t *testing.T) {
 		EmailAddress: "test123@example.com",
 		Prefix:       "dmag_d8K",
 		Token:        "$2a$14$eIWUgPMqNQbpPveJdoQ8sOSw7DY5zBXUP3uUhm31LrfbArv6ZIhXe",
-		GeneratedAt:  "2022-12-30 18:09:35",
-		ExpiredAt:    "2023-06-30 18:09:35",
+		GeneratedAt:  time.Now().Format(Layout2),
+		ExpiredAt:    time.Now().AddDate(1, 0, 0).Format(Layout2),
 	}
 	testCases := []struct {
 		name          string
@@ -150,6 +152,271 @@ func TestPricer(t *testing.T) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 			},
 		},
+		{
+			name:  "HISTOGRAM",
+			token: "dmag_d8K.RGbV3hb3LEwYohYW",
+			body: gin.H{
+				"stocks":               []string{"AAPL", "AVGO", "TSLA"},
+				"strike":               0.80,
+				"autocall_coupon_rate": 0.50,
+				"barrier_coupon_rate":  0.20,
+				"fixed_coupon_rate":    0.20,
+				"knock_out_barrier":    1.05,
+				"knock_in_barrier":     0.70,
+				"coupon_barrier":       0.80,
+				"maturity":             12,
+				"frequency":            3,
+				"isEuro":               true,
+				"histogram_bins":       20,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, token string) {
+				authorizationHeader := fmt.Sprintf("%s %s", authorizationTypeBearer, token)
+				request.Header.Set(authorizationHeaderKey, authorizationHeader)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
+				store.EXPECT().GetValues(gomock.Any()).Times(1).Return(values, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				var res pricerResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+				require.NotNil(t, res.Distribution)
+				require.Len(t, res.Distribution.Histogram, 20)
+				require.LessOrEqual(t, res.Distribution.Percentiles["5"], res.Distribution.Percentiles["50"])
+			},
+		},
+		{
+			name:  "VALUATION_DATE",
+			token: "dmag_d8K.RGbV3hb3LEwYohYW",
+			body: gin.H{
+				"stocks":               []string{"AAPL", "AVGO", "TSLA"},
+				"strike":               0.80,
+				"autocall_coupon_rate": 0.50,
+				"barrier_coupon_rate":  0.20,
+				"fixed_coupon_rate":    0.20,
+				"knock_out_barrier":    1.05,
+				"knock_in_barrier":     0.70,
+				"coupon_barrier":       0.80,
+				"maturity":             12,
+				"frequency":            3,
+				"isEuro":               true,
+				"valuation_date":       "2022-12-30",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, token string) {
+				authorizationHeader := fmt.Sprintf("%s %s", authorizationTypeBearer, token)
+				request.Header.Set(authorizationHeaderKey, authorizationHeader)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				asOf := values
+				asOf.ParamDate, asOf.StatsDate, asOf.CorrDate = "2022-12-28", "2022-12-28", "2022-12-28"
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
+				store.EXPECT().GetValues(gomock.Any()).Times(0)
+				store.EXPECT().GetValuesAsOf(gomock.Any(), gomock.Eq("2022-12-30")).Times(1).Return(asOf, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				var res pricerResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+				require.Equal(t, marketDataDates{Params: "2022-12-28", Stats: "2022-12-28", Corr: "2022-12-28"}, res.MarketData)
+			},
+		},
+		{
+			name:  "INVALID_VALUATION_DATE",
+			token: "dmag_d8K.RGbV3hb3LEwYohYW",
+			body: gin.H{
+				"stocks":               []string{"AAPL", "AVGO", "TSLA"},
+				"strike":               0.80,
+				"autocall_coupon_rate": 0.50,
+				"barrier_coupon_rate":  0.20,
+				"fixed_coupon_rate":    0.20,
+				"knock_out_barrier":    1.05,
+				"knock_in_barrier":     0.70,
+				"coupon_barrier":       0.80,
+				"maturity":             12,
+				"frequency":            3,
+				"isEuro":               true,
+				"valuation_date":       "2099-01-02",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, token string) {
+				authorizationHeader := fmt.Sprintf("%s %s", authorizationTypeBearer, token)
+				request.Header.Set(authorizationHeaderKey, authorizationHeader)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
+				store.EXPECT().GetValuesAsOf(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "MID_LIFE",
+			token: "dmag_d8K.RGbV3hb3LEwYohYW",
+			body: gin.H{
+				"stocks":               []string{"AAPL", "AVGO", "TSLA"},
+				"strike":               0.80,
+				"autocall_coupon_rate": 0.50,
+				"barrier_coupon_rate":  0.20,
+				"fixed_coupon_rate":    0.20,
+				"knock_out_barrier":    1.05,
+				"knock_in_barrier":     0.70,
+				"coupon_barrier":       0.80,
+				"maturity":             12,
+				"frequency":            3,
+				"isEuro":               true,
+				"strike_date":          time.Now().AddDate(0, -4, 0).Format(Layout),
+				"initial_fixings":      gin.H{"aapl": 150.0, "AVGO": 600.0, "TSLA": 200.0},
+				"history":              gin.H{"knocked_in": true},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, token string) {
+				authorizationHeader := fmt.Sprintf("%s %s", authorizationTypeBearer, token)
+				request.Header.Set(authorizationHeaderKey, authorizationHeader)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
+				store.EXPECT().GetValues(gomock.Any()).Times(1).Return(values, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				var res pricerResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+				require.Equal(t, 1.0, res.Schedule.KnockInProbability)
+				require.Less(t, len(res.Schedule.Dates), 4)
+			},
+		},
+		{
+			name:  "MISSING_INITIAL_FIXINGS",
+			token: "dmag_d8K.RGbV3hb3LEwYohYW",
+			body: gin.H{
+				"stocks":               []string{"AAPL", "AVGO", "TSLA"},
+				"strike":               0.80,
+				"autocall_coupon_rate": 0.50,
+				"barrier_coupon_rate":  0.20,
+				"fixed_coupon_rate":    0.20,
+				"knock_out_barrier":    1.05,
+				"knock_in_barrier":     0.70,
+				"coupon_barrier":       0.80,
+				"maturity":             12,
+				"frequency":            3,
+				"isEuro":               true,
+				"strike_date":          time.Now().AddDate(0, -4, 0).Format(Layout),
+				"initial_fixings":      gin.H{"AAPL": 150.0},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, token string) {
+				authorizationHeader := fmt.Sprintf("%s %s", authorizationTypeBearer, token)
+				request.Header.Set(authorizationHeaderKey, authorizationHeader)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
+				store.EXPECT().GetValues(gomock.Any()).Times(1).Return(values, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "JOINT_CALENDAR",
+			token: "dmag_d8K.RGbV3hb3LEwYohYW",
+			body: gin.H{
+				"stocks":                  []string{"AAPL", "AVGO", "TSLA"},
+				"strike":                  0.80,
+				"autocall_coupon_rate":    0.50,
+				"barrier_coupon_rate":     0.20,
+				"fixed_coupon_rate":       0.20,
+				"knock_out_barrier":       1.05,
+				"knock_in_barrier":        0.70,
+				"coupon_barrier":          0.80,
+				"maturity":                12,
+				"frequency":               3,
+				"isEuro":                  true,
+				"stock_calendars":         gin.H{"TSLA": "XETRA", "AVGO": "TSE"},
+				"disruption":              "common",
+				"business_day_convention": "modified_following",
+				"end_of_month":            true,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, token string) {
+				authorizationHeader := fmt.Sprintf("%s %s", authorizationTypeBearer, token)
+				request.Header.Set(authorizationHeaderKey, authorizationHeader)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
+				store.EXPECT().GetValues(gomock.Any()).Times(1).Return(values, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "INDIVIDUAL_POSTPONEMENT",
+			token: "dmag_d8K.RGbV3hb3LEwYohYW",
+			body: gin.H{
+				"stocks":                  []string{"AAPL", "AVGO", "TSLA"},
+				"strike":                  0.80,
+				"autocall_coupon_rate":    0.50,
+				"barrier_coupon_rate":     0.20,
+				"fixed_coupon_rate":       0.20,
+				"knock_out_barrier":       1.05,
+				"knock_in_barrier":        0.70,
+				"coupon_barrier":          0.80,
+				"maturity":                12,
+				"frequency":               3,
+				"isEuro":                  true,
+				"stock_calendars":         gin.H{"TSLA": "XETRA", "AVGO": "TSE"},
+				"disruption":              "individual",
+				"business_day_convention": "modified_following",
+				"end_of_month":            true,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, token string) {
+				authorizationHeader := fmt.Sprintf("%s %s", authorizationTypeBearer, token)
+				request.Header.Set(authorizationHeaderKey, authorizationHeader)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
+				store.EXPECT().GetValues(gomock.Any()).Times(1).Return(values, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "SETTLEMENT_LAG",
+			token: "dmag_d8K.RGbV3hb3LEwYohYW",
+			body: gin.H{
+				"stocks":               []string{"AAPL", "AVGO", "TSLA"},
+				"strike":               0.80,
+				"autocall_coupon_rate": 0.50,
+				"barrier_coupon_rate":  0.20,
+				"fixed_coupon_rate":    0.20,
+				"knock_out_barrier":    1.05,
+				"knock_in_barrier":     0.70,
+				"coupon_barrier":       0.80,
+				"maturity":             12,
+				"frequency":            3,
+				"isEuro":               true,
+				"valuation_date":       "2022-12-28",
+				"settlement_lag":       2,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, token string) {
+				authorizationHeader := fmt.Sprintf("%s %s", authorizationTypeBearer, token)
+				request.Header.Set(authorizationHeaderKey, authorizationHeader)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
+				store.EXPECT().GetValuesAsOf(gomock.Any(), gomock.Eq("2022-12-28")).Times(1).Return(values, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				var res pricerResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Len(t, res.Schedule.Dates, 4)
+				require.Equal(t, "2023-03-28", res.Schedule.Dates[0].Date)
+				require.Equal(t, "2023-03-30", res.Schedule.Dates[0].PaymentDate)
+			},
+		},
 		{
 			name:  "ERROR_BINDING",
 			token: "dmag_d8K.RGbV3hb3LEwYohYW",
@@ -178,6 +445,192 @@ func TestPricer(t *testing.T) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
+		{
+			name:  "PHYSICAL_SETTLEMENT",
+			token: "dmag_d8K.RGbV3hb3LEwYohYW",
+			body: gin.H{
+				"stocks":               []string{"AAPL", "AVGO", "TSLA"},
+				"strike":               0.80,
+				"autocall_coupon_rate": 0.50,
+				"barrier_coupon_rate":  0.20,
+				"fixed_coupon_rate":    0.20,
+				"knock_out_barrier":    1.05,
+				"knock_in_barrier":     0.70,
+				"coupon_barrier":       0.80,
+				"maturity":             12,
+				"frequency":            3,
+				"isEuro":               true,
+				"settlement":           "physical",
+				"denomination":         10000,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, token string) {
+				authorizationHeader := fmt.Sprintf("%s %s", authorizationTypeBearer, token)
+				request.Header.Set(authorizationHeaderKey, authorizationHeader)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
+				store.EXPECT().GetValues(gomock.Any()).Times(1).Return(values, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				var res pricerResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+				total := 0.0
+				for _, p := range res.Delivery {
+					total += p
+				}
+				require.InDelta(t, 1.0, total, 1e-9)
+				require.Len(t, res.Schedule.Dates, 4)
+				require.GreaterOrEqual(t, res.Schedule.KnockInProbability, res.Delivery["AAPL"]+res.Delivery["AVGO"]+res.Delivery["TSLA"]-1e-9)
+			},
+		},
+		{
+			name:  "CONTINUOUS_KI_MONITORING",
+			token: "dmag_d8K.RGbV3hb3LEwYohYW",
+			body: gin.H{
+				"stocks":               []string{"AAPL", "AVGO", "TSLA"},
+				"strike":               0.80,
+				"autocall_coupon_rate": 0.50,
+				"barrier_coupon_rate":  0.20,
+				"fixed_coupon_rate":    0.20,
+				"knock_out_barrier":    1.05,
+				"knock_in_barrier":     0.70,
+				"coupon_barrier":       0.80,
+				"maturity":             12,
+				"frequency":            3,
+				"ki_monitoring":        "continuous",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, token string) {
+				authorizationHeader := fmt.Sprintf("%s %s", authorizationTypeBearer, token)
+				request.Header.Set(authorizationHeaderKey, authorizationHeader)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
+				store.EXPECT().GetValues(gomock.Any()).Times(1).Return(values, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "QUANTO",
+			token: "dmag_d8K.RGbV3hb3LEwYohYW",
+			body: gin.H{
+				"stocks":               []string{"AAPL", "AVGO", "TSLA"},
+				"strike":               0.80,
+				"autocall_coupon_rate": 0.50,
+				"barrier_coupon_rate":  0.20,
+				"fixed_coupon_rate":    0.20,
+				"knock_out_barrier":    1.05,
+				"knock_in_barrier":     0.70,
+				"coupon_barrier":       0.80,
+				"maturity":             12,
+				"frequency":            3,
+				"isEuro":               true,
+				"currency":             "EUR",
+				"fx":                   gin.H{"USD": gin.H{"rate": 0.92, "vol": 0.08, "corr": gin.H{"AAPL": 0.2, "TSLA": -0.1}}},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, token string) {
+				authorizationHeader := fmt.Sprintf("%s %s", authorizationTypeBearer, token)
+				request.Header.Set(authorizationHeaderKey, authorizationHeader)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
+				store.EXPECT().GetValues(gomock.Any()).Times(1).Return(values, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "INVALID_FX_RATE",
+			token: "dmag_d8K.RGbV3hb3LEwYohYW",
+			body: gin.H{
+				"stocks":               []string{"AAPL", "AVGO", "TSLA"},
+				"strike":               0.80,
+				"autocall_coupon_rate": 0.50,
+				"barrier_coupon_rate":  0.20,
+				"fixed_coupon_rate":    0.20,
+				"knock_out_barrier":    1.05,
+				"knock_in_barrier":     0.70,
+				"coupon_barrier":       0.80,
+				"maturity":             12,
+				"frequency":            3,
+				"isEuro":               true,
+				"currency":             "EUR",
+				"fx":                   gin.H{"USD": gin.H{"rate": 0, "vol": 0.08}},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, token string) {
+				authorizationHeader := fmt.Sprintf("%s %s", authorizationTypeBearer, token)
+				request.Header.Set(authorizationHeaderKey, authorizationHeader)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
+				store.EXPECT().GetValues(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "UNSUPPORTED_PRODUCT",
+			token: "dmag_d8K.RGbV3hb3LEwYohYW",
+			body: gin.H{
+				"product_type":         "swap",
+				"stocks":               []string{"AAPL", "AVGO", "TSLA"},
+				"strike":               0.80,
+				"autocall_coupon_rate": 0.50,
+				"barrier_coupon_rate":  0.20,
+				"fixed_coupon_rate":    0.20,
+				"knock_out_barrier":    1.05,
+				"knock_in_barrier":     0.70,
+				"coupon_barrier":       0.80,
+				"maturity":             12,
+				"frequency":            3,
+				"isEuro":               true,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, token string) {
+				authorizationHeader := fmt.Sprintf("%s %s", authorizationTypeBearer, token)
+				request.Header.Set(authorizationHeaderKey, authorizationHeader)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
+				store.EXPECT().GetValues(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "BARRIER_SCHEDULE_MISMATCH",
+			token: "dmag_d8K.RGbV3hb3LEwYohYW",
+			body: gin.H{
+				"stocks":               []string{"AAPL", "AVGO", "TSLA"},
+				"strike":               0.80,
+				"autocall_coupon_rate": 0.50,
+				"barrier_coupon_rate":  0.20,
+				"fixed_coupon_rate":    0.20,
+				"knock_out_barrier":    1.05,
+				"knock_out_schedule":   []float64{1.00, 0.98},
+				"knock_in_barrier":     0.70,
+				"coupon_barrier":       0.80,
+				"maturity":             12,
+				"frequency":            3,
+				"isEuro":               true,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, token string) {
+				authorizationHeader := fmt.Sprintf("%s %s", authorizationTypeBearer, token)
+				request.Header.Set(authorizationHeaderKey, authorizationHeader)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(value, nil)
+				store.EXPECT().GetValues(gomock.Any()).Times(1).Return(values, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
 		{
 			name:  "EMPTY_STOCK_LIST",
 			token: "dmag_d8K.RGbV3hb3LEwYohYW",
@@ -483,3 +936,35 @@ func TestFCNPricer(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckProductFields(t *testing.T) {
+	testCases := []struct {
+		name  string
+		arg   pricerRequest
+		isErr bool
+	}{
+		{name: "FCN", arg: pricerRequest{Cpn: 0.1, BarrierCpn: 0.2, FixCpn: 0.2, KO: 1.05, KI: 0.7, KC: 0.8, NonCall: 1}},
+		{name: "FCN_MEMORY", arg: pricerRequest{ProductType: payoff.ProductFCN, Memory: true}, isErr: true},
+		{name: "PHOENIX", arg: pricerRequest{ProductType: payoff.ProductPhoenix, BarrierCpn: 0.2, KO: 1.05, KI: 0.7, KC: 0.8, Memory: true}},
+		{name: "PHOENIX_AUTOCALL_COUPON", arg: pricerRequest{ProductType: payoff.ProductPhoenix, Cpn: 0.1}, isErr: true},
+		{name: "RC", arg: pricerRequest{ProductType: payoff.ProductReverseConvertible, FixCpn: 0.1, KI: 0.7, KIMonitoring: "daily"}},
+		{name: "RC_KNOCK_OUT", arg: pricerRequest{ProductType: payoff.ProductReverseConvertible, FixCpn: 0.1, KO: 1.05}, isErr: true},
+		{name: "RC_KNOCK_OUT_SCHEDULE", arg: pricerRequest{ProductType: payoff.ProductReverseConvertible, KOSchedule: []float64{1.0, 0.95}}, isErr: true},
+		{name: "ELN", arg: pricerRequest{ProductType: payoff.ProductELN, Strike: 0.9}},
+		{name: "ELN_COUPON", arg: pricerRequest{ProductType: payoff.ProductELN, FixCpn: 0.1}, isErr: true},
+		{name: "SNOWBALL", arg: pricerRequest{ProductType: payoff.ProductSnowball, Cpn: 0.12, KO: 1.03, KI: 0.75, KOSchedule: []float64{1.03, 1.0}}},
+		{name: "SNOWBALL_COUPON_BARRIER_SCHEDULE", arg: pricerRequest{ProductType: payoff.ProductSnowball, KCSchedule: []float64{0.8, 0.8}}, isErr: true},
+		{name: "ACCUMULATOR", arg: pricerRequest{ProductType: payoff.ProductAccumulator, KO: 1.05, Gearing: 2}},
+		{name: "DECUMULATOR_KNOCK_IN", arg: pricerRequest{ProductType: payoff.ProductDecumulator, KI: 0.7}, isErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkProductFields(tc.arg)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	db "github.com/banachtech/spotted-zebra/db/sqlc"
+	"github.com/banachtech/spotted-zebra/mc"
+	"github.com/banachtech/spotted-zebra/payoff"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Closing prices of a stock in date order
+type closeSeries struct {
+	dates []time.Time
+	px    []float64
+}
+
+// Group daily closes by ticker. Rows are ordered by ticker and date.
+func newCloseSeries(rows []db.Dailyclose) (map[string]*closeSeries, error) {
+	out := map[string]*closeSeries{}
+	for _, v := range rows {
+		d, err := time.Parse(Layout, v.Date)
+		if err != nil {
+			return nil, err
+		}
+		s, ok := out[v.Ticker]
+		if !ok {
+			s = &closeSeries{}
+			out[v.Ticker] = s
+		}
+		s.dates = append(s.dates, d)
+		s.px = append(s.px, v.Close)
+	}
+	return out, nil
+}
+
+// Last close on or before d
+func (s *closeSeries) asOf(d time.Time) (float64, bool) {
+	i := sort.Search(len(s.dates), func(i int) bool { return s.dates[i].After(d) })
+	if i == 0 {
+		return 0, false
+	}
+	return s.px[i-1], true
+}
+
+// Path of closes on the observation dates relative to the close on the first date. Dates without
+// a close take the previous one.
+func realisedPath(stocks []string, dates []time.Time, closes map[string]*closeSeries) (mc.MCPath, error) {
+	path := mc.MCPath{}
+	for _, v := range stocks {
+		s, ok := closes[v]
+		if !ok {
+			return nil, fmt.Errorf("no closing prices for %s", v)
+		}
+		x0, ok := s.asOf(dates[0])
+		if !ok || x0 <= 0 {
+			return nil, fmt.Errorf("no close for %s on %s", v, dates[0].Format(Layout))
+		}
+		path[v] = make([]float64, len(dates))
+		for i, d := range dates {
+			x, _ := s.asOf(d)
+			path[v][i] = x / x0
+		}
+	}
+	return path, nil
+}
+
+// Check whether every stock has closed on or after d
+func closedThrough(stocks []string, d time.Time, closes map[string]*closeSeries) bool {
+	for _, v := range stocks {
+		s, ok := closes[v]
+		if !ok || len(s.dates) == 0 || s.dates[len(s.dates)-1].Before(d) {
+			return false
+		}
+	}
+	return true
+}
+
+// Price a note issued on a backtest date with the market data of that date, and pay it out on
+// the realised closes. Notes that have not matured by the last close are reported as live.
+func realisedNote(date string, stocks []string, arg pricerRequest, fixings, means map[string]float64, models map[string]mc.Model, corrMatrix *mat.SymDense, closes map[string]*closeSeries) (backtestNote, bool, error) {
+	note := backtestNote{Date: date}
+	start, err := time.Parse(Layout, date)
+	if err != nil {
+		return note, false, err
+	}
+	arg.ValuationDate = date
+
+	dates, err := scheduleDates(stocks, arg, start)
+	if err != nil {
+		return note, false, err
+	}
+	vols := atmVols(models, float64(arg.Maturity)/12.0)
+	settleFixings, err := settlementFixings(stocks, arg, fixings)
+	if err != nil {
+		return note, false, err
+	}
+	product, err := newPayoff(stocks, arg, settleFixings, vols, dates)
+	if err != nil {
+		return note, false, err
+	}
+	if product, err = postponed(stocks, arg, start, product, dates); err != nil {
+		return note, false, err
+	}
+	pay, err := paymentDates(stocks, arg)
+	if err != nil {
+		return note, false, err
+	}
+	settled := payoff.NewSettled(product, pay)
+
+	obsdates := settled.Dates()
+	if !closedThrough(stocks, obsdates[len(obsdates)-1], closes) {
+		return note, true, nil
+	}
+	path, err := realisedPath(stocks, obsdates, closes)
+	if err != nil {
+		return note, false, err
+	}
+
+	price, err := fcnPricer(stocks, arg, fixings, means, fixings, models, corrMatrix)
+	if err != nil {
+		return note, false, err
+	}
+	note = newBacktestNote(start, price, settled.Cashflows(path))
+	if math.IsNaN(note.PnL) {
+		return note, false, errors.New("return is NaN")
+	}
+	return note, false, nil
+}
+
+// Realised outcomes of the notes issued on each backtest date, and the number of notes still live.
+func realisedNotes(dates []string, stocks []string, arg pricerRequest, fixings, means map[string]map[string]float64, models map[string]map[string]mc.Model, corrMatrix map[string]*mat.SymDense, closes map[string]*closeSeries) ([]backtestNote, int, error) {
+	var wg sync.WaitGroup
+	notes := make([]backtestNote, len(dates))
+	live := make([]bool, len(dates))
+	errs := make([]error, len(dates))
+	for t := range dates {
+		wg.Add(1)
+		go func(t int) {
+			defer wg.Done()
+			d := dates[t]
+			notes[t], live[t], errs[t] = realisedNote(d, stocks, arg, fixings[d], means[d], models[d], corrMatrix[d], closes)
+		}(t)
+	}
+	wg.Wait()
+
+	var out []backtestNote
+	nlive := 0
+	for t := range dates {
+		if errs[t] != nil {
+			return nil, 0, errs[t]
+		}
+		if live[t] {
+			nlive++
+			continue
+		}
+		out = append(out, notes[t])
+	}
+	return out, nlive, nil
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	db "github.com/banachtech/spotted-zebra/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+// Daily closes of the stocks on every weekday from start to end, moving by step each day
+func dailyCloses(stocks []string, start, end string, x0, step float64) []db.Dailyclose {
+	var out []db.Dailyclose
+	t0, _ := time.Parse(Layout, start)
+	t1, _ := time.Parse(Layout, end)
+	for _, v := range stocks {
+		x := x0
+		for d := t0; !d.After(t1); d = d.AddDate(0, 0, 1) {
+			if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+				continue
+			}
+			out = append(out, db.Dailyclose{Date: d.Format(Layout), Ticker: v, Close: x})
+			x += step
+		}
+	}
+	return out
+}
+
+func TestRealisedPath(t *testing.T) {
+	closes, err := newCloseSeries([]db.Dailyclose{
+		{Date: "2023-01-03", Ticker: "AAPL", Close: 100},
+		{Date: "2023-01-04", Ticker: "AAPL", Close: 110},
+		{Date: "2023-01-06", Ticker: "AAPL", Close: 90},
+		{Date: "2023-01-04", Ticker: "TSLA", Close: 50},
+		{Date: "2023-01-05", Ticker: "TSLA", Close: 40},
+	})
+	require.NoError(t, err)
+
+	d := func(s string) time.Time {
+		out, err := time.Parse(Layout, s)
+		require.NoError(t, err)
+		return out
+	}
+	dates := []time.Time{d("2023-01-04"), d("2023-01-05"), d("2023-01-06")}
+
+	path, err := realisedPath([]string{"AAPL", "TSLA"}, dates, closes)
+	require.NoError(t, err)
+	require.Equal(t, []float64{1, 1, 90.0 / 110}, path["AAPL"])
+	require.Equal(t, []float64{1, 0.8, 0.8}, path["TSLA"])
+
+	require.True(t, closedThrough([]string{"AAPL"}, dates[2], closes))
+	require.False(t, closedThrough([]string{"AAPL", "TSLA"}, dates[2], closes))
+
+	_, err = realisedPath([]string{"TSLA"}, []time.Time{d("2023-01-03"), d("2023-01-04")}, closes)
+	require.Error(t, err)
+	_, err = realisedPath([]string{"GOOG"}, dates, closes)
+	require.Error(t, err)
+}
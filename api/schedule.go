@@ -0,0 +1,79 @@
+package api
+
+import (
+	"time"
+
+	"github.com/banachtech/spotted-zebra/payoff"
+)
+
+// Expected cashflows and barrier events of a product across simulated paths
+type scheduleResult struct {
+	Dates               []scheduleEntry `json:"dates"`
+	KnockInProbability  float64         `json:"knock_in_probability"`
+	ExpectedLife        float64         `json:"expected_life"`
+	ExpectedLossGivenKI float64         `json:"expected_loss_given_knock_in"`
+}
+
+// Probability of knocking out and expected coupon paid in the period ending on a schedule date,
+// and the date they are paid
+type scheduleEntry struct {
+	Date                string  `json:"date"`
+	PaymentDate         string  `json:"payment_date"`
+	AutocallProbability float64 `json:"autocall_probability"`
+	ExpectedCoupon      float64 `json:"expected_coupon"`
+}
+
+// Aggregate simulated path cashflows into a schedule on the coupon dates. Knock-outs and coupons
+// are attributed to the first schedule date on or after they occur, paid on its pay date. The expected life is in years
+// from the first observation date t0 to knock-out or maturity T, and the loss given knock-in is
+// the expected shortfall of the principal redeemed, in cash or shares, on paths that knocked in.
+func cashflowSchedule(cfs [][]payoff.Cashflow, dates []time.Time, pay func(time.Time) time.Time, t0, T time.Time) scheduleResult {
+	res := scheduleResult{Dates: make([]scheduleEntry, len(dates))}
+	for i, d := range dates {
+		res.Dates[i].Date = d.Format(Layout)
+		res.Dates[i].PaymentDate = pay(d).Format(Layout)
+	}
+	period := func(t time.Time) int {
+		for i, d := range dates {
+			if !d.Before(t) {
+				return i
+			}
+		}
+		return len(dates) - 1
+	}
+
+	n := float64(len(cfs))
+	nki := 0.0
+	for _, path := range cfs {
+		end := T
+		isKI := false
+		principal := 0.0
+		for _, cf := range path {
+			switch cf.Type {
+			case payoff.FixedCoupon, payoff.BarrierCoupon, payoff.AutocallCoupon:
+				if len(dates) > 0 {
+					res.Dates[period(cf.Date)].ExpectedCoupon += cf.Amount / n
+				}
+			case payoff.KnockOut:
+				end = cf.Date
+				if len(dates) > 0 {
+					res.Dates[period(cf.Date)].AutocallProbability += 1 / n
+				}
+			case payoff.KnockIn:
+				isKI = true
+			case payoff.Redemption, payoff.Delivery, payoff.FractionalCash:
+				principal += cf.Amount
+			}
+		}
+		res.ExpectedLife += payoff.YearFrac(t0, end) / n
+		if isKI {
+			nki++
+			res.ExpectedLossGivenKI += 1 - principal
+		}
+	}
+	res.KnockInProbability = nki / n
+	if nki > 0 {
+		res.ExpectedLossGivenKI /= nki
+	}
+	return res
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/banachtech/spotted-zebra/calendar"
+	"github.com/banachtech/spotted-zebra/payoff"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCashflowSchedule(t *testing.T) {
+	t0 := time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC)
+	dates := []time.Time{t0.AddDate(0, 0, 73), t0.AddDate(0, 0, 146), t0.AddDate(0, 0, 365)}
+	T := dates[2]
+
+	cfs := [][]payoff.Cashflow{
+		// Knocks out on the first date
+		{
+			{Date: dates[0], Type: payoff.FixedCoupon, Amount: 0.02},
+			{Date: dates[0], Type: payoff.AutocallCoupon, Amount: 0.01},
+			{Date: dates[0], Type: payoff.KnockOut},
+			{Date: dates[0], Type: payoff.Redemption, Amount: 1.0},
+		},
+		// Knocks in between the first two dates and redeems at a loss
+		{
+			{Date: dates[0], Type: payoff.FixedCoupon, Amount: 0.02},
+			{Date: dates[0].AddDate(0, 0, 10), Type: payoff.KnockIn},
+			{Date: dates[1], Type: payoff.FixedCoupon, Amount: 0.02},
+			{Date: dates[2], Type: payoff.FixedCoupon, Amount: 0.02},
+			{Date: T, Type: payoff.Redemption, Amount: 0.7},
+		},
+		// Knocks in and is physically settled
+		{
+			{Date: T, Type: payoff.KnockIn},
+			{Date: T, Type: payoff.Delivery, Amount: 0.8, Ticker: "AAPL", Shares: 0.01},
+			{Date: T, Type: payoff.FractionalCash, Amount: 0.1, Ticker: "AAPL"},
+		},
+		// Redeems at par
+		{
+			{Date: T, Type: payoff.Redemption, Amount: 1.0},
+		},
+	}
+
+	pay := func(d time.Time) time.Time { return calendar.NYSE.AddBusinessDays(d, 2) }
+	res := cashflowSchedule(cfs, dates, pay, t0, T)
+	require.Len(t, res.Dates, 3)
+	require.Equal(t, "2023-03-17", res.Dates[0].Date)
+	require.Equal(t, "2023-03-21", res.Dates[0].PaymentDate)
+	require.InDelta(t, 0.25, res.Dates[0].AutocallProbability, 1e-12)
+	require.InDelta(t, 0, res.Dates[1].AutocallProbability, 1e-12)
+	require.InDelta(t, 0.05/4, res.Dates[0].ExpectedCoupon, 1e-12)
+	require.InDelta(t, 0.02/4, res.Dates[1].ExpectedCoupon, 1e-12)
+	require.InDelta(t, 0.5, res.KnockInProbability, 1e-12)
+	require.InDelta(t, (0.2+3*1.0)/4, res.ExpectedLife, 1e-12)
+	require.InDelta(t, (0.3+0.1)/2, res.ExpectedLossGivenKI, 1e-12)
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"encoding/csv"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/banachtech/spotted-zebra/payoff"
+	"github.com/gin-gonic/gin"
+	"gonum.org/v1/gonum/stat"
+)
+
+// Outcome of a note issued on a backtest date. Payout is discounted to the issue date and coupons
+// are the undiscounted coupons received. The redemption date is the date the last cashflow is paid.
+type backtestNote struct {
+	Date           string  `json:"date"`
+	Price          float64 `json:"price"`
+	Payout         float64 `json:"payout"`
+	PnL            float64 `json:"pnl"`
+	AutocallDate   string  `json:"autocall_date,omitempty"`
+	KnockedIn      bool    `json:"knocked_in"`
+	Coupons        float64 `json:"coupons"`
+	RedemptionDate string  `json:"redemption_date"`
+
+	issued    time.Time
+	redeemed  time.Time
+	cashflows []payoff.Cashflow
+}
+
+// Outcome of a note issued on t0 at a price and paying cfs
+func newBacktestNote(t0 time.Time, price float64, cfs []payoff.Cashflow) backtestNote {
+	note := backtestNote{Date: t0.Format(Layout), Price: price, Payout: payoff.PV(cfs, t0), issued: t0, redeemed: t0, cashflows: cfs}
+	note.PnL = note.Payout - note.Price
+	for _, cf := range cfs {
+		if cf.Paid().After(note.redeemed) {
+			note.redeemed = cf.Paid()
+		}
+		switch cf.Type {
+		case payoff.KnockOut:
+			note.AutocallDate = cf.Date.Format(Layout)
+		case payoff.KnockIn:
+			note.KnockedIn = true
+		case payoff.FixedCoupon, payoff.BarrierCoupon, payoff.AutocallCoupon:
+			note.Coupons += cf.Amount
+		}
+	}
+	note.RedemptionDate = note.redeemed.Format(Layout)
+	return note
+}
+
+// Respond with the backtest series of notes in date order, as CSV or as JSON with the PnL
+// statistics, the analytics of the rolling issuance strategy and any extra fields.
+func backtestResponse(c *gin.Context, format string, notes []backtestNote, extra gin.H) {
+	if format == "csv" {
+		c.Header("Content-Type", "text/csv")
+		c.Header("Content-Disposition", `attachment; filename="backtest.csv"`)
+		c.Status(http.StatusOK)
+		if err := writeBacktestCSV(c.Writer, notes); err != nil {
+			c.Error(err)
+		}
+		return
+	}
+
+	profit := make([]float64, len(notes))
+	for i, v := range notes {
+		profit[i] = v.PnL
+	}
+	mean, std := stat.MeanStdDev(profit, nil)
+	min, max := minmax(profit)
+
+	a := newBacktestAnalytics(notes)
+
+	res := gin.H{
+		"mean":              mean,
+		"std":               std,
+		"min":               min,
+		"max":               max,
+		"annualised_return": a.AnnualisedReturn,
+		"volatility":        a.Volatility,
+		"sharpe":            a.Sharpe,
+		"sortino":           a.Sortino,
+		"max_drawdown":      a.MaxDrawdown,
+		"autocall_rate":     a.AutocallRate,
+		"knock_in_rate":     a.KnockInRate,
+		"average_life":      a.AverageLife,
+		"nav":               a.NAV,
+		"series":            notes,
+	}
+	for k, v := range extra {
+		res[k] = v
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// Write the backtest series as CSV with a header row
+func writeBacktestCSV(w io.Writer, notes []backtestNote) error {
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"date", "price", "payout", "pnl", "autocall_date", "knocked_in", "coupons", "redemption_date"}); err != nil {
+		return err
+	}
+	f := func(x float64) string { return strconv.FormatFloat(x, 'f', -1, 64) }
+	for _, v := range notes {
+		row := []string{v.Date, f(v.Price), f(v.Payout), f(v.PnL), v.AutocallDate, strconv.FormatBool(v.KnockedIn), f(v.Coupons), v.RedemptionDate}
+		if err := out.Write(row); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
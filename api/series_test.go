@@ -0,0 +1,46 @@
+package api
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/banachtech/spotted-zebra/payoff"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBacktestSeries(t *testing.T) {
+	t0 := time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC)
+	t1 := t0.AddDate(0, 3, 0)
+
+	notes := []backtestNote{
+		newBacktestNote(t0, 0.98, []payoff.Cashflow{
+			{Date: t1, Type: payoff.FixedCoupon, Amount: 0.02},
+			{Date: t1, Type: payoff.AutocallCoupon, Amount: 0.01},
+			{Date: t1, Type: payoff.KnockOut},
+			{Date: t1, Type: payoff.Redemption, Amount: 1.0},
+		}),
+		newBacktestNote(t0.AddDate(0, 0, 1), 0.98, []payoff.Cashflow{
+			{Date: t1, Type: payoff.KnockIn},
+			{Date: t1, Type: payoff.Redemption, Amount: 0.5},
+		}),
+	}
+	require.Equal(t, "2023-04-03", notes[0].AutocallDate)
+	require.False(t, notes[0].KnockedIn)
+	require.InDelta(t, 0.03, notes[0].Coupons, 1e-12)
+	require.InDelta(t, payoff.PV([]payoff.Cashflow{{Date: t1, Amount: 1.03}}, t0)-0.98, notes[0].PnL, 1e-12)
+	require.Empty(t, notes[1].AutocallDate)
+	require.True(t, notes[1].KnockedIn)
+	require.Zero(t, notes[1].Coupons)
+	require.Equal(t, "2023-04-03", notes[1].RedemptionDate)
+
+	var buf bytes.Buffer
+	require.NoError(t, writeBacktestCSV(&buf, notes[1:]))
+	require.Equal(t, "date,price,payout,pnl,autocall_date,knocked_in,coupons,redemption_date\n2023-01-04,0.98,"+
+		ftoa(notes[1].Payout)+","+ftoa(notes[1].PnL)+",,true,0,2023-04-03\n", buf.String())
+}
+
+func ftoa(x float64) string {
+	return strconv.FormatFloat(x, 'f', -1, 64)
+}
@@ -11,6 +11,7 @@ var DefaultStocks = []string{"AAPL", "AMZN", "META", "MSFT", "TSLA", "GOOG", "NV
 type Server struct {
 	store  db.Store
 	router *gin.Engine
+	admins map[string]bool
 }
 
 // NewServer creates a new HTTP server and set up routing.
@@ -26,10 +27,23 @@ func (server *Server) setupRouter() {
 
 	authRoutes := router.Group("/v1").Use(server.authentication)
 	authRoutes.POST("/pricer", server.pricer)
+	authRoutes.POST("/termsheet", server.termSheetPricer)
 	authRoutes.POST("/backtest", server.backtest)
+	authRoutes.GET("/calendars", server.listCalendars)
+	authRoutes.PUT("/calendars/:name", server.adminOnly, server.uploadCalendar)
+	authRoutes.GET("/calendars/:name/business_days", server.businessDays)
 	server.router = router
 }
 
+// SetAdmins sets the API key prefixes allowed to change data shared by every user, such as
+// holiday calendars.
+func (server *Server) SetAdmins(prefixes []string) {
+	server.admins = map[string]bool{}
+	for _, v := range prefixes {
+		server.admins[v] = true
+	}
+}
+
 // Start runs the HTTP server on a specific address.
 func (server *Server) Start(address string) error {
 	return server.router.Run(address)
@@ -0,0 +1,253 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/banachtech/spotted-zebra/calendar"
+	db "github.com/banachtech/spotted-zebra/db/sqlc"
+	"github.com/banachtech/spotted-zebra/mc"
+	"github.com/banachtech/spotted-zebra/payoff"
+	"github.com/banachtech/spotted-zebra/util"
+	"github.com/gin-gonic/gin"
+)
+
+// An FCN described by its full term sheet rather than a maturity and frequency in months. A note
+// struck before the valuation date is valued on its initial fixings and observed history.
+type termSheetRequest struct {
+	Stocks             []string             `json:"stocks" binding:"required"`
+	Strike             float64              `json:"strike" binding:"required"`
+	KI                 float64              `json:"knock_in_barrier"`
+	KIMonitoring       string               `json:"ki_monitoring" binding:"omitempty,oneof=daily continuous european"`
+	TradeDate          string               `json:"trade_date" binding:"required"`
+	StrikeDate         string               `json:"strike_date" binding:"required"`
+	Observations       []observationRequest `json:"observations" binding:"required,min=1,dive"`
+	SettlementLag      int                  `json:"settlement_lag" binding:"min=0,max=30"`
+	FinalValuationDate string               `json:"final_valuation_date" binding:"required"`
+	Calendars          map[string]string    `json:"stock_calendars"`
+	ValuationDate      string               `json:"valuation_date"`
+	InitialFixings     map[string]float64   `json:"initial_fixings"`
+	History            historyRequest       `json:"history"`
+}
+
+// A coupon observation date of a term sheet. The note can only knock out on dates with a
+// knock-out barrier. Coupons are amounts per unit notional paid on the date, the barrier coupon
+// only if the basket is above the coupon barrier.
+type observationRequest struct {
+	Date          string  `json:"date" binding:"required"`
+	KO            float64 `json:"knock_out_barrier" binding:"min=0"`
+	KC            float64 `json:"coupon_barrier" binding:"min=0"`
+	FixedCoupon   float64 `json:"fixed_coupon"`
+	BarrierCoupon float64 `json:"barrier_coupon"`
+}
+
+func (server *Server) termSheetPricer(c *gin.Context) {
+	var req termSheetRequest
+
+	prefix, exists := c.Get("prefix")
+	if !exists {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest, "msg": "Authentication Error"})
+		return
+	}
+
+	limiter := getPricerLimiter(prefix.(string))
+
+	// Check if the user has exceeded the rate limit
+	if !limiter.Allow() {
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"status": http.StatusTooManyRequests, "msg": "Too Many Requests"})
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if len(req.Stocks) == 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest, "msg": "Error JSON binding, please check your JSON input"})
+		return
+	}
+
+	sort.Strings(DefaultStocks)
+
+	filterStocks, err := util.Filter(req.Stocks, DefaultStocks)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest, "msg": fmt.Sprintf("Failed filter stocks: %v", err)})
+		return
+	}
+	req.Stocks = filterStocks
+
+	cals, err := stockCalendars(filterStocks, req.Calendars)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	cal := calendar.Join("", cals...)
+	dates, err := termSheetDates(req, cal)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest, "msg": fmt.Sprintf("Invalid term sheet: %v", err)})
+		return
+	}
+	tNow, err := valuationDate(req.ValuationDate)
+	if err != nil || tNow.After(time.Now()) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest, "msg": fmt.Sprintf("Invalid valuation date: %s", req.ValuationDate)})
+		return
+	}
+	struck := dates["mcdates"][0].Before(tNow)
+	var fixings map[string]float64
+	if struck {
+		if fixings, err = initialFixings(filterStocks, req.InitialFixings); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+	}
+
+	var result db.GetValuesResult
+	if req.ValuationDate != "" {
+		result, err = server.store.GetValuesAsOf(c, req.ValuationDate)
+	} else {
+		result, err = server.store.GetValues(c)
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.AbortWithStatusJSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		c.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	models, spotFixings, means, px, corrMatrix := constructor(result, filterStocks)
+	if !struck {
+		fixings = spotFixings
+	}
+
+	mcdates := dates["mcdates"]
+	T := payoff.YearFrac(mcdates[0], mcdates[len(mcdates)-1])
+	fcn, err := newTermSheetFCN(filterStocks, req, atmVols(models, T), dates)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest, "msg": fmt.Sprintf("Invalid term sheet: %v", err)})
+		return
+	}
+
+	if struck {
+		if err := setHistory(fcn, tNow, req.History); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest, "msg": fmt.Sprintf("Invalid term sheet: %v", err)})
+			return
+		}
+	}
+
+	settled := payoff.NewSettled(fcn, func(d time.Time) time.Time {
+		return cal.AddBusinessDays(d, req.SettlementLag)
+	})
+	cfs, err := mcCashflows(filterStocks, settled, mc.NewBasket(models), fixings, means, px, corrMatrix)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"status": http.StatusBadRequest, "msg": fmt.Sprintf("Failed compute FCN price: %s", err)})
+		return
+	}
+
+	settlement := settled.PayDate(mcdates[len(mcdates)-1])
+	t0 := fcn.Dates()[0]
+	var cpndates []time.Time
+	for _, v := range dates["cpndates"] {
+		if v.After(t0) {
+			cpndates = append(cpndates, v)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"price":             mcPrice(cfs, t0),
+		"schedule":          cashflowSchedule(cfs, cpndates, settled.PayDate, t0, mcdates[len(mcdates)-1]),
+		"settlement_date":   settlement.Format(Layout),
+		"market_data_dates": marketDataDates{Params: result.ParamDate, Stats: result.StatsDate, Corr: result.CorrDate},
+	})
+}
+
+// Validate the term sheet dates against the joint calendar of the stocks and generate the monte-carlo,
+// coupon and knock-out observation dates. The simulation runs from the strike date to the final
+// valuation date.
+func termSheetDates(req termSheetRequest, cal *calendar.Calendar) (map[string][]time.Time, error) {
+	parse := func(name, s string) (time.Time, error) {
+		d, err := time.Parse(Layout, s)
+		if err != nil {
+			return d, fmt.Errorf("invalid %s: %s", name, s)
+		}
+		if !cal.IsBusinessDay(d) {
+			return d, fmt.Errorf("%s %s is not a business day", name, s)
+		}
+		return d, nil
+	}
+
+	tradeDate, err := parse("trade date", req.TradeDate)
+	if err != nil {
+		return nil, err
+	}
+	strikeDate, err := parse("strike date", req.StrikeDate)
+	if err != nil {
+		return nil, err
+	}
+	if strikeDate.Before(tradeDate) {
+		return nil, errors.New("strike date cannot be before trade date")
+	}
+	finalDate, err := parse("final valuation date", req.FinalValuationDate)
+	if err != nil {
+		return nil, err
+	}
+
+	var cpndates, kodates []time.Time
+	prev := strikeDate
+	for _, v := range req.Observations {
+		d, err := parse("observation date", v.Date)
+		if err != nil {
+			return nil, err
+		}
+		if !d.After(prev) {
+			return nil, fmt.Errorf("observation date %s must be after %s", v.Date, prev.Format(Layout))
+		}
+		cpndates = append(cpndates, d)
+		if v.KO > 0 {
+			kodates = append(kodates, d)
+		}
+		prev = d
+	}
+	if finalDate.Before(prev) {
+		return nil, errors.New("final valuation date cannot be before the last observation date")
+	}
+
+	mcdates, err := cal.BusinessDates(strikeDate, finalDate)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]time.Time{"mcdates": mcdates, "cpndates": cpndates, "kodates": kodates}, nil
+}
+
+// Convert a term sheet into an FCN on the generated observation dates.
+func newTermSheetFCN(stocks []string, req termSheetRequest, vols map[string]float64, dates map[string][]time.Time) (*payoff.FCN, error) {
+	var ko, kc, fixed, barrier []float64
+	for _, v := range req.Observations {
+		if v.KO > 0 {
+			ko = append(ko, v.KO)
+		}
+		kc = append(kc, v.KC)
+		fixed = append(fixed, v.FixedCoupon)
+		barrier = append(barrier, v.BarrierCoupon)
+	}
+
+	fcn := payoff.NewFCN(stocks, req.Strike, 0, 0, 0, 0, req.KI, 0, len(req.Observations), 1, false, dates)
+	if err := fcn.SetBarrierSchedule(ko, kc); err != nil {
+		return nil, err
+	}
+	if err := fcn.SetCouponSchedule(fixed, barrier); err != nil {
+		return nil, err
+	}
+	if req.KIMonitoring != "" {
+		if err := fcn.SetKIMonitoring(req.KIMonitoring, vols); err != nil {
+			return nil, err
+		}
+	}
+	return fcn, nil
+}
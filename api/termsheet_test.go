@@ -0,0 +1,236 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/banachtech/spotted-zebra/calendar"
+	mockdb "github.com/banachtech/spotted-zebra/db/mock"
+	db "github.com/banachtech/spotted-zebra/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTermSheetDates(t *testing.T) {
+	observations := []observationRequest{
+		{Date: "2023-04-03", KC: 0.8, FixedCoupon: 0.02},
+		{Date: "2023-07-03", KO: 1.0, KC: 0.8, FixedCoupon: 0.02, BarrierCoupon: 0.03},
+		{Date: "2023-10-02", KO: 0.98, KC: 0.75, FixedCoupon: 0.02, BarrierCoupon: 0.03},
+	}
+
+	type testCases struct {
+		name string
+		req  termSheetRequest
+		nko  int
+		err  bool
+	}
+
+	for _, test := range []testCases{
+		{
+			name: "OK",
+			req:  termSheetRequest{TradeDate: "2022-12-28", StrikeDate: "2023-01-03", Observations: observations, FinalValuationDate: "2023-10-02"},
+			nko:  2,
+		},
+		{
+			name: "STRIKE_BEFORE_TRADE",
+			req:  termSheetRequest{TradeDate: "2023-01-04", StrikeDate: "2023-01-03", Observations: observations, FinalValuationDate: "2023-10-02"},
+			err:  true,
+		},
+		{
+			name: "HOLIDAY",
+			req:  termSheetRequest{TradeDate: "2022-12-28", StrikeDate: "2023-01-02", Observations: observations, FinalValuationDate: "2023-10-02"},
+			err:  true,
+		},
+		{
+			name: "UNORDERED_OBSERVATIONS",
+			req:  termSheetRequest{TradeDate: "2022-12-28", StrikeDate: "2023-01-03", Observations: []observationRequest{observations[1], observations[0]}, FinalValuationDate: "2023-10-02"},
+			err:  true,
+		},
+		{
+			name: "FINAL_BEFORE_LAST_OBSERVATION",
+			req:  termSheetRequest{TradeDate: "2022-12-28", StrikeDate: "2023-01-03", Observations: observations, FinalValuationDate: "2023-09-29"},
+			err:  true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			dates, err := termSheetDates(test.req, calendar.NYSE)
+			if test.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, len(test.req.Observations), len(dates["cpndates"]))
+			require.Equal(t, test.nko, len(dates["kodates"]))
+			require.True(t, dates["mcdates"][0].Equal(time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC)))
+
+			fcn, err := newTermSheetFCN([]string{"AAPL"}, test.req, nil, dates)
+			require.NoError(t, err)
+			require.Equal(t, []float64{1.0, 0.98}, fcn.KOSchedule)
+			require.Equal(t, []float64{0, 0.03, 0.03}, fcn.BarrierCpns)
+		})
+	}
+}
+
+func TestTermSheetPricer(t *testing.T) {
+	values := db.GetValuesResult{
+		Params: []db.Modelparameter{
+			{Date: "2022-12-28", Ticker: "AAPL", Sigma: 0.38956884573910466, Alpha: 0.31754204762725213, Beta: 0.09668058826922904, Kappa: 18.55196217354717, Rho: -0.08156231110497626},
+			{Date: "2022-12-28", Ticker: "TSLA", Sigma: 0.926280232995074, Alpha: 0.09316279525141707, Beta: 0.11993430192118938, Kappa: 167.74229696983923, Rho: 0.9999999982622454},
+		},
+		Stats: []db.Statistic{
+			{Date: "2022-12-28", Ticker: "AAPL", Mean: -0.0024065238291240444, Fixing: 130.03},
+			{Date: "2022-12-28", Ticker: "TSLA", Mean: -0.015126507431615293, Fixing: 109.1},
+		},
+		Corrpair: []db.Corrpair{
+			{Date: "2022-12-28", X0: "AAPL", X1: "TSLA", Corr: 0.5498852123024683},
+		},
+		LatestPrice: []db.GetLatestPriceRow{
+			{Ticker: "AAPL", Fixing: 130.03},
+			{Ticker: "TSLA", Fixing: 109.1},
+		},
+		ParamDate: "2022-12-28",
+		StatsDate: "2022-12-28",
+		CorrDate:  "2022-12-28",
+	}
+	prefix := "dmag_d8K"
+	user := db.User{
+		EmailAddress: "test123@example.com",
+		Prefix:       prefix,
+		Token:        "$2a$14$eIWUgPMqNQbpPveJdoQ8sOSw7DY5zBXUP3uUhm31LrfbArv6ZIhXe",
+		GeneratedAt:  time.Now().Format(Layout2),
+		ExpiredAt:    time.Now().AddDate(1, 0, 0).Format(Layout2),
+	}
+	termSheet := func(extra gin.H) gin.H {
+		out := gin.H{
+			"stocks":           []string{"AAPL", "TSLA"},
+			"strike":           0.80,
+			"knock_in_barrier": 0.60,
+			"trade_date":       "2022-12-28",
+			"strike_date":      "2023-01-03",
+			"observations": []gin.H{
+				{"date": "2023-04-03", "coupon_barrier": 0.8, "fixed_coupon": 0.02},
+				{"date": "2023-07-03", "knock_out_barrier": 1.0, "coupon_barrier": 0.8, "fixed_coupon": 0.02},
+				{"date": "2023-10-02", "knock_out_barrier": 0.98, "coupon_barrier": 0.75, "fixed_coupon": 0.02},
+			},
+			"final_valuation_date": "2023-10-02",
+			"valuation_date":       "2023-05-01",
+			"initial_fixings":      gin.H{"AAPL": 125.07, "TSLA": 108.1},
+		}
+		for k, v := range extra {
+			out[k] = v
+		}
+		return out
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "STRUCK",
+			body: termSheet(gin.H{"settlement_lag": 2}),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetValues(gomock.Any()).Times(0)
+				store.EXPECT().GetValuesAsOf(gomock.Any(), gomock.Eq("2023-05-01")).Times(1).Return(values, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				var res struct {
+					Price          float64 `json:"price"`
+					SettlementDate string  `json:"settlement_date"`
+					Schedule       struct {
+						Dates []scheduleEntry `json:"dates"`
+					} `json:"schedule"`
+					MarketData marketDataDates `json:"market_data_dates"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Greater(t, res.Price, 0.0)
+				require.Equal(t, "2023-10-04", res.SettlementDate)
+				require.Len(t, res.Schedule.Dates, 2)
+				require.Equal(t, marketDataDates{Params: "2022-12-28", Stats: "2022-12-28", Corr: "2022-12-28"}, res.MarketData)
+			},
+		},
+		{
+			name: "MISSING_INITIAL_FIXINGS",
+			body: termSheet(gin.H{"initial_fixings": gin.H{"AAPL": 125.07}}),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetValuesAsOf(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "MATURED",
+			body: termSheet(gin.H{"valuation_date": "2023-10-03"}),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetValuesAsOf(gomock.Any(), gomock.Eq("2023-10-03")).Times(1).Return(values, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "FUTURE_VALUATION_DATE",
+			body: termSheet(gin.H{"valuation_date": time.Now().AddDate(0, 0, 7).Format(Layout)}),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetValuesAsOf(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "EXCHANGE_HOLIDAY",
+			body: termSheet(gin.H{"stock_calendars": gin.H{"TSLA": "XETRA"}, "final_valuation_date": "2023-12-26"}),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetValuesAsOf(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "SETTLEMENT_LAG_TOO_LONG",
+			body: termSheet(gin.H{"settlement_lag": 31}),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetValuesAsOf(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUser(gomock.Any(), gomock.Eq(prefix)).Times(1).Return(user, nil)
+			tc.buildStubs(store)
+
+			server := NewServer(store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/v1/termsheet", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, "dmag_d8K.RGbV3hb3LEwYohYW"))
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,235 @@
+// Package calendar generates exchange holiday calendars from holiday rules and provides the
+// business day arithmetic used to build observation schedules.
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// A Calendar of exchange holidays generated from rules, and listed dates for holidays that follow
+// no rule. Saturdays and Sundays are never business days. Business days between IndexFrom and
+// IndexTo are precomputed on first use, so rules and dates must not change afterwards. Through is
+// the last year covered by the listed dates, or zero if the rules give every holiday. Schedules
+// reaching past it are rejected, as their holidays are not known.
+type Calendar struct {
+	Name    string
+	Rules   []Rule
+	Dates   []time.Time
+	Through int
+
+	once sync.Once
+	idx  *index
+}
+
+var (
+	joinMu sync.Mutex
+	joined = map[string]joint{}
+)
+
+// A cached joint calendar and the calendars it combines
+type joint struct {
+	cal  *Calendar
+	from []*Calendar
+}
+
+// Create a calendar from holiday rules
+func New(name string, rules ...Rule) *Calendar {
+	return &Calendar{Name: name, Rules: rules}
+}
+
+// Combine calendars into a joint calendar on which a day is a business day only if it is a
+// business day on every calendar. Joint calendars are cached for reuse by name and rebuilt when
+// a calendar of the same name has been replaced.
+func Join(name string, cals ...*Calendar) *Calendar {
+	key := name
+	for _, c := range cals {
+		key += "|" + c.Name
+	}
+	joinMu.Lock()
+	defer joinMu.Unlock()
+	if j, ok := joined[key]; ok && sameCalendars(j.from, cals) {
+		return j.cal
+	}
+
+	out := &Calendar{Name: name}
+	for _, c := range cals {
+		out.Rules = append(out.Rules, c.Rules...)
+		out.Dates = append(out.Dates, c.Dates...)
+		if c.Through > 0 && (out.Through == 0 || c.Through < out.Through) {
+			out.Through = c.Through
+		}
+	}
+	joined[key] = joint{cal: out, from: append([]*Calendar{}, cals...)}
+	return out
+}
+
+// Drop the cached joint calendars combining a calendar
+func forgetJoined(c *Calendar) {
+	joinMu.Lock()
+	defer joinMu.Unlock()
+	for k, j := range joined {
+		for _, v := range j.from {
+			if v == c {
+				delete(joined, k)
+				break
+			}
+		}
+	}
+}
+
+// Check whether two lists hold the same calendars in the same order
+func sameCalendars(a, b []*Calendar) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Check that the holidays of the year of d are known
+func (c *Calendar) Covers(d time.Time) error {
+	if c.Through > 0 && d.Year() > c.Through {
+		return fmt.Errorf("%s holidays are only listed through %d", c.Name, c.Through)
+	}
+	return nil
+}
+
+// Precomputed business days
+func (c *Calendar) index() *index {
+	c.once.Do(func() { c.idx = newIndex(c) })
+	return c.idx
+}
+
+// Sorted weekday holidays observed in a year. A holiday of an adjacent year may be observed in
+// this one.
+func (c *Calendar) Holidays(year int) []time.Time {
+	var out []time.Time
+	for y := year - 1; y <= year+1; y++ {
+		for _, r := range c.Rules {
+			d, ok := r.Date(y)
+			if ok && d.Year() == year && isWeekday(d) {
+				out = append(out, d)
+			}
+		}
+	}
+	for _, d := range c.Dates {
+		if d.Year() == year && isWeekday(d) {
+			out = append(out, d)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Before(out[j]) })
+	return out
+}
+
+// Check whether d is a holiday
+func (c *Calendar) IsHoliday(d time.Time) bool {
+	if idx, i := c.index(), dayNumber(d); idx.contains(i) {
+		return isWeekday(d) && !idx.isSet(i)
+	}
+	return c.isListedHoliday(d)
+}
+
+// Check whether d is a holiday from the rules and listed dates
+func (c *Calendar) isListedHoliday(d time.Time) bool {
+	y, m, day := d.Date()
+	for _, h := range c.Holidays(y) {
+		if h.Month() == m && h.Day() == day {
+			return true
+		}
+	}
+	return false
+}
+
+// Check whether d is a weekday and not a holiday
+func (c *Calendar) IsBusinessDay(d time.Time) bool {
+	if idx, i := c.index(), dayNumber(d); idx.contains(i) {
+		return idx.isSet(i)
+	}
+	return isWeekday(d) && !c.isListedHoliday(d)
+}
+
+// Roll d forward to the next business day, unless it is one
+func (c *Calendar) AdjustFollowing(d time.Time) time.Time {
+	for !c.IsBusinessDay(d) {
+		d = d.AddDate(0, 0, 1)
+	}
+	return d
+}
+
+// Move d by n business days, backwards for negative n
+func (c *Calendar) AddBusinessDays(d time.Time, n int) time.Time {
+	if n == 0 {
+		return d
+	}
+	if idx, i := c.index(), dayNumber(d); idx.contains(i) {
+		// The first business day after d is at rank(i), or rank(i)+1 if d is a business day
+		k := idx.rank(i) + n
+		if n > 0 && !idx.isSet(i) {
+			k--
+		}
+		if k >= 0 && k < len(idx.days) {
+			return d.AddDate(0, 0, int(idx.days[k])-i)
+		}
+	}
+
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+	for n > 0 {
+		d = d.AddDate(0, 0, step)
+		if c.IsBusinessDay(d) {
+			n--
+		}
+	}
+	return d
+}
+
+// Number of business days between start and end, both included
+func (c *Calendar) CountBusinessDays(start, end time.Time) int {
+	if end.Before(start) {
+		return 0
+	}
+	idx, i, j := c.index(), dayNumber(start), dayNumber(end)
+	if idx.contains(i) && idx.contains(j+1) {
+		return idx.rank(j+1) - idx.rank(i)
+	}
+	n := 0
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		if c.IsBusinessDay(d) {
+			n++
+		}
+	}
+	return n
+}
+
+// Return the start date followed by the business days after it up to and including the end date.
+// The end date must be in a year whose holidays are listed.
+func (c *Calendar) BusinessDates(start, end time.Time) ([]time.Time, error) {
+	if end.Before(start) {
+		return nil, errors.New("end date must be later than start date")
+	}
+	if err := c.Covers(end); err != nil {
+		return nil, err
+	}
+	out := []time.Time{start}
+	for {
+		start = c.AdjustFollowing(start.AddDate(0, 0, 1))
+		if start.After(end) {
+			return out, nil
+		}
+		out = append(out, start)
+	}
+}
+
+func isWeekday(d time.Time) bool {
+	return d.Weekday() != time.Saturday && d.Weekday() != time.Sunday
+}
@@ -0,0 +1,329 @@
+package calendar
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func parse(t *testing.T, s ...string) []time.Time {
+	out := make([]time.Time, len(s))
+	for i, v := range s {
+		d, err := time.Parse("2006-01-02", v)
+		require.NoError(t, err)
+		out[i] = d
+	}
+	return out
+}
+
+func TestNYSEHolidays(t *testing.T) {
+	type testCases struct {
+		year int
+		hols []string
+	}
+
+	for _, test := range []testCases{
+		{year: 2022, hols: []string{"2022-01-17", "2022-02-21", "2022-04-15", "2022-05-30", "2022-06-20", "2022-07-04", "2022-09-05", "2022-11-24", "2022-12-26"}},
+		{year: 2023, hols: []string{"2023-01-02", "2023-01-16", "2023-02-20", "2023-04-07", "2023-05-29", "2023-06-19", "2023-07-04", "2023-09-04", "2023-11-23", "2023-12-25"}},
+		{year: 2024, hols: []string{"2024-01-01", "2024-01-15", "2024-02-19", "2024-03-29", "2024-05-27", "2024-06-19", "2024-07-04", "2024-09-02", "2024-11-28", "2024-12-25"}},
+		{year: 2027, hols: []string{"2027-01-01", "2027-01-18", "2027-02-15", "2027-03-26", "2027-05-31", "2027-06-18", "2027-07-05", "2027-09-06", "2027-11-25", "2027-12-24"}},
+	} {
+		t.Run(time.Date(test.year, 1, 1, 0, 0, 0, 0, time.UTC).Format("2006"), func(t *testing.T) {
+			require.Equal(t, parse(t, test.hols...), NYSE.Holidays(test.year))
+		})
+	}
+}
+
+func TestEasterSunday(t *testing.T) {
+	for _, v := range parse(t, "2000-04-23", "2019-04-21", "2024-03-31", "2025-04-20", "2038-04-25") {
+		require.True(t, EasterSunday(v.Year()).Equal(v))
+	}
+}
+
+func TestBusinessDays(t *testing.T) {
+	d := parse(t, "2022-01-01", "2023-12-22", "2023-12-25", "2023-12-26", "2023-12-29", "2024-01-02")
+
+	require.False(t, NYSE.IsHoliday(d[0]))
+	require.False(t, NYSE.IsBusinessDay(d[0]))
+	require.True(t, NYSE.IsHoliday(d[2]))
+	require.True(t, NYSE.AdjustFollowing(d[2]).Equal(d[3]))
+	require.True(t, NYSE.AddBusinessDays(d[1], 1).Equal(d[3]))
+	require.True(t, NYSE.AddBusinessDays(d[3], -1).Equal(d[1]))
+	require.True(t, NYSE.AddBusinessDays(d[4], 1).Equal(d[5]))
+
+	dates, err := NYSE.BusinessDates(d[1], d[5])
+	require.NoError(t, err)
+	require.Len(t, dates, 6)
+	require.True(t, dates[1].Equal(d[3]))
+
+	_, err = NYSE.BusinessDates(d[5], d[1])
+	require.Error(t, err)
+}
+
+func TestExchangeHolidays(t *testing.T) {
+	type testCases struct {
+		name string
+		year int
+		hols []string
+	}
+
+	for _, test := range []testCases{
+		{name: "TSE", year: 2024, hols: []string{"2024-01-01", "2024-01-02", "2024-01-03", "2024-01-08", "2024-02-12", "2024-02-23", "2024-03-20", "2024-04-29", "2024-05-03", "2024-05-06", "2024-07-15", "2024-08-12", "2024-09-16", "2024-09-23", "2024-10-14", "2024-11-04", "2024-12-31"}},
+		{name: "XETRA", year: 2024, hols: []string{"2024-01-01", "2024-03-29", "2024-04-01", "2024-05-01", "2024-12-24", "2024-12-25", "2024-12-26", "2024-12-31"}},
+		{name: "hkex", year: 2024, hols: []string{"2024-01-01", "2024-02-12", "2024-02-13", "2024-03-29", "2024-04-01", "2024-04-04", "2024-05-01", "2024-05-15", "2024-06-10", "2024-07-01", "2024-09-18", "2024-10-01", "2024-10-11", "2024-12-25", "2024-12-26"}},
+		{name: "HKEX", year: 2026, hols: []string{"2026-01-01", "2026-02-17", "2026-02-18", "2026-02-19", "2026-04-03", "2026-04-06", "2026-04-07", "2026-05-01", "2026-05-25", "2026-06-19", "2026-07-01", "2026-10-01", "2026-10-19", "2026-12-25"}},
+		{name: "SGX", year: 2026, hols: []string{"2026-01-01", "2026-02-17", "2026-02-18", "2026-04-03", "2026-05-01", "2026-05-27", "2026-06-01", "2026-08-10", "2026-11-09", "2026-12-25"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			cal, err := Get(test.name)
+			require.NoError(t, err)
+			require.Equal(t, parse(t, test.hols...), cal.Holidays(test.year))
+		})
+	}
+
+	// Christmas Day on a Sunday is observed after Boxing Day in Hong Kong
+	require.True(t, HKEX.IsHoliday(parse(t, "2022-12-27")[0]))
+
+	_, err := Get("LSE")
+	require.Error(t, err)
+	require.Equal(t, []string{"HKEX", "NYSE", "SGX", "TSE", "XETRA"}, Names())
+}
+
+func TestCovers(t *testing.T) {
+	d := parse(t, "2026-12-31", "2027-02-08")
+
+	require.NoError(t, NYSE.Covers(d[1]))
+	require.NoError(t, HKEX.Covers(d[0]))
+	require.Error(t, HKEX.Covers(d[1]))
+	require.Error(t, SGX.Covers(d[1]))
+
+	joint := Join("NYSE+SGX", NYSE, SGX)
+	require.Equal(t, 2026, joint.Through)
+	_, err := joint.Adjust(d[1], Following)
+	require.Error(t, err)
+	_, err = joint.BusinessDates(d[0], d[1])
+	require.Error(t, err)
+
+	c, err := FromDates("TEST_COVERS", []string{"2024-01-02", "2025-01-02"})
+	require.NoError(t, err)
+	require.Equal(t, 2025, c.Through)
+}
+
+func TestJoin(t *testing.T) {
+	joint := Join("NYSE+XETRA", NYSE, XETRA)
+	d := parse(t, "2024-05-01", "2024-05-27", "2024-05-02")
+	require.True(t, joint.IsHoliday(d[0]))
+	require.True(t, joint.IsHoliday(d[1]))
+	require.True(t, joint.IsBusinessDay(d[2]))
+	require.False(t, NYSE.IsHoliday(d[0]))
+}
+
+func TestAdjust(t *testing.T) {
+	// 2024-03-29 is Good Friday and 2024-03-30 a Saturday, 2024-06-01 a Saturday
+	d := parse(t, "2024-03-30", "2024-06-01")
+
+	type testCases struct {
+		convention string
+		out        []string
+		isErr      bool
+	}
+
+	for _, test := range []testCases{
+		{convention: Following, out: []string{"2024-04-01", "2024-06-03"}},
+		{convention: ModifiedFollowing, out: []string{"2024-03-28", "2024-06-03"}},
+		{convention: Preceding, out: []string{"2024-03-28", "2024-05-31"}},
+		{convention: ModifiedPreceding, out: []string{"2024-03-28", "2024-06-03"}},
+		{convention: Unadjusted, out: []string{"2024-03-30", "2024-06-01"}},
+		{convention: "nearest", isErr: true},
+	} {
+		t.Run(test.convention, func(t *testing.T) {
+			for i, v := range d {
+				out, err := NYSE.Adjust(v, test.convention)
+				if test.isErr {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+				require.Equal(t, test.out[i], out.Format("2006-01-02"))
+			}
+		})
+	}
+}
+
+func TestAddMonths(t *testing.T) {
+	type testCases struct {
+		name  string
+		start string
+		n     int
+		eom   bool
+		out   string
+	}
+
+	for _, test := range []testCases{
+		{name: "CAPPED", start: "2024-01-31", n: 1, out: "2024-02-29"},
+		{name: "MID_MONTH", start: "2024-01-15", n: 3, out: "2024-04-15"},
+		{name: "NO_EOM", start: "2024-02-29", n: 1, out: "2024-03-29"},
+		{name: "EOM", start: "2024-02-29", n: 1, eom: true, out: "2024-03-28"},
+		{name: "EOM_BUSINESS_DAY", start: "2024-05-31", n: 1, eom: true, out: "2024-06-28"},
+		{name: "EOM_NOT_MONTH_END", start: "2024-05-30", n: 1, eom: true, out: "2024-06-30"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			start := parse(t, test.start)[0]
+			require.Equal(t, test.out, NYSE.AddMonths(start, test.n, test.eom).Format("2006-01-02"))
+		})
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	c, err := ParseJSON(strings.NewReader(`{"name": "lse", "holidays": ["2024-01-01", "2024-08-26"]}`))
+	require.NoError(t, err)
+	require.Equal(t, "LSE", c.Name)
+	require.Equal(t, parse(t, "2024-01-01", "2024-08-26"), c.Dates)
+	require.False(t, c.IsBusinessDay(c.Dates[1]))
+
+	_, err = ParseJSON(strings.NewReader(`{"name": "LSE", "holidays": ["2024-13-01"]}`))
+	require.Error(t, err)
+	_, err = ParseJSON(strings.NewReader(`{"holidays": ["2024-01-01"]}`))
+	require.Error(t, err)
+}
+
+func TestParseICS(t *testing.T) {
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"X-WR-CALNAME:ASX",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20241225",
+		"DTEND;VALUE=DATE:20241227",
+		"SUMMARY:Christmas and",
+		"  Boxing Day",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART:20240126T000000Z",
+		"SUMMARY:Australia Day",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	c, err := ParseICS("", strings.NewReader(ics))
+	require.NoError(t, err)
+	require.Equal(t, "ASX", c.Name)
+	require.Equal(t, parse(t, "2024-12-25", "2024-12-26", "2024-01-26"), c.Dates)
+
+	c, err = ParseICS("asx_custom", strings.NewReader(ics))
+	require.NoError(t, err)
+	require.Equal(t, "ASX_CUSTOM", c.Name)
+
+	_, err = ParseICS("ASX", strings.NewReader("BEGIN:VEVENT\nDTSTART;VALUE=DATE:20240101\nRRULE:FREQ=YEARLY\nEND:VEVENT"))
+	require.Error(t, err)
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "lse.json"), []byte(`{"name": "LSE", "holidays": ["2024-01-01"]}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "asx.ics"), []byte("BEGIN:VEVENT\nDTSTART;VALUE=DATE:20240126\nEND:VEVENT\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("notes"), 0o644))
+
+	cals, err := LoadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, cals, 2)
+	require.Equal(t, "ASX", cals[0].Name)
+	require.Equal(t, "LSE", cals[1].Name)
+}
+
+func TestRegister(t *testing.T) {
+	Register(&Calendar{Name: "TEST_REGISTER", Dates: parse(t, "2024-01-02")})
+	c, err := Get("test_register")
+	require.NoError(t, err)
+	require.True(t, c.IsHoliday(parse(t, "2024-01-02")[0]))
+	require.Contains(t, Names(), "TEST_REGISTER")
+}
+
+func TestDefault(t *testing.T) {
+	require.Same(t, NYSE, Default())
+	defer Register(NYSE)
+	c := &Calendar{Name: DefaultName}
+	Register(c)
+	require.Same(t, c, Default())
+}
+
+func TestIndex(t *testing.T) {
+	for _, c := range []*Calendar{NYSE, HKEX, Join("NYSE+TSE", NYSE, TSE)} {
+		t.Run(c.Name, func(t *testing.T) {
+			start := parse(t, "2022-12-20")[0]
+			for d := start; d.Year() < 2025; d = d.AddDate(0, 0, 1) {
+				require.Equal(t, isWeekday(d) && !c.isListedHoliday(d), c.IsBusinessDay(d), d)
+				for _, n := range []int{-3, -1, 1, 2, 10} {
+					want := d
+					for k, step := n, n/abs(n); k != 0; k -= step {
+						want = want.AddDate(0, 0, step)
+						for !isWeekday(want) || c.isListedHoliday(want) {
+							want = want.AddDate(0, 0, step)
+						}
+					}
+					require.Equal(t, want, c.AddBusinessDays(d, n), "%v %d", d, n)
+				}
+			}
+		})
+	}
+}
+
+func TestIndexBounds(t *testing.T) {
+	d := parse(t, "1989-12-29", "1990-01-02", "2100-12-31", "2101-01-03")
+	require.Equal(t, d[1], NYSE.AddBusinessDays(d[0], 1))
+	require.Equal(t, d[0], NYSE.AddBusinessDays(d[1], -1))
+	require.Equal(t, d[3], NYSE.AddBusinessDays(d[2], 1))
+	require.False(t, NYSE.IsBusinessDay(parse(t, "2101-01-01")[0]))
+	require.Equal(t, 3, NYSE.CountBusinessDays(d[0], d[1].AddDate(0, 0, 1)))
+}
+
+func TestCountBusinessDays(t *testing.T) {
+	d := parse(t, "2024-03-24", "2024-04-02", "2024-03-28", "2024-03-29")
+	require.Equal(t, 6, NYSE.CountBusinessDays(d[0], d[1]))
+	require.Equal(t, 1, NYSE.CountBusinessDays(d[2], d[2]))
+	require.Equal(t, 0, NYSE.CountBusinessDays(d[3], d[3]))
+	require.Equal(t, 0, NYSE.CountBusinessDays(d[1], d[0]))
+	require.Equal(t, 250, NYSE.CountBusinessDays(parse(t, "2023-01-01")[0], parse(t, "2023-12-31")[0]))
+}
+
+func TestJoinCache(t *testing.T) {
+	require.Same(t, Join("", NYSE, XETRA), Join("", NYSE, XETRA))
+	require.NotSame(t, Join("", NYSE, XETRA), Join("", XETRA, NYSE))
+
+	// A replaced calendar of the same name is not served from the cache
+	old := &Calendar{Name: "TEST_JOIN", Dates: parse(t, "2024-01-02")}
+	Register(old)
+	joint := Join("", NYSE, old)
+	require.Same(t, joint, Join("", NYSE, old))
+	require.True(t, joint.IsHoliday(date(2024, time.January, 2)))
+
+	replaced := &Calendar{Name: "TEST_JOIN", Dates: parse(t, "2024-01-03")}
+	Register(replaced)
+	joinMu.Lock()
+	for _, j := range joined {
+		for _, c := range j.from {
+			require.NotSame(t, old, c)
+		}
+	}
+	joinMu.Unlock()
+	joint = Join("", NYSE, replaced)
+	require.False(t, joint.IsHoliday(date(2024, time.January, 2)))
+	require.True(t, joint.IsHoliday(date(2024, time.January, 3)))
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func BenchmarkAddBusinessDays(b *testing.B) {
+	d := date(2024, time.January, 2)
+	for i := 0; i < b.N; i++ {
+		NYSE.AddBusinessDays(d, 250)
+	}
+}
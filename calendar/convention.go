@@ -0,0 +1,72 @@
+package calendar
+
+import (
+	"fmt"
+	"time"
+)
+
+// Business day conventions for rolling a date falling on a holiday
+const (
+	Following         = "following"
+	ModifiedFollowing = "modified_following"
+	Preceding         = "preceding"
+	ModifiedPreceding = "modified_preceding"
+	Unadjusted        = "unadjusted"
+)
+
+// Roll d onto a business day by a business day convention. The modified conventions roll the
+// other way if the adjusted date would fall in another month. Dates in years whose holidays are
+// not listed are rejected.
+func (c *Calendar) Adjust(d time.Time, convention string) (time.Time, error) {
+	if err := c.Covers(d); err != nil {
+		return d, err
+	}
+	switch convention {
+	case "", Following:
+		return c.AdjustFollowing(d), nil
+	case ModifiedFollowing:
+		if t := c.AdjustFollowing(d); t.Month() == d.Month() {
+			return t, nil
+		}
+		return c.AdjustPreceding(d), nil
+	case Preceding:
+		return c.AdjustPreceding(d), nil
+	case ModifiedPreceding:
+		if t := c.AdjustPreceding(d); t.Month() == d.Month() {
+			return t, nil
+		}
+		return c.AdjustFollowing(d), nil
+	case Unadjusted:
+		return d, nil
+	default:
+		return d, fmt.Errorf("unsupported business day convention: %s", convention)
+	}
+}
+
+// Roll d back to the previous business day, unless it is one
+func (c *Calendar) AdjustPreceding(d time.Time) time.Time {
+	for !c.IsBusinessDay(d) {
+		d = d.AddDate(0, 0, -1)
+	}
+	return d
+}
+
+// Roll start by n months. The day is capped at the length of the target month, so that Jan 31
+// rolls to the end of February. With the end-of-month rule, a start on the last business day of
+// its month rolls to the last business day of the target month.
+func (c *Calendar) AddMonths(start time.Time, n int, eom bool) time.Time {
+	y, m, d := start.Date()
+	last := time.Date(y, m+time.Month(n)+1, 0, 0, 0, 0, 0, start.Location())
+	if eom && c.isMonthEnd(start) {
+		return c.AdjustPreceding(last)
+	}
+	if d > last.Day() {
+		d = last.Day()
+	}
+	return time.Date(y, m+time.Month(n), d, 0, 0, 0, 0, start.Location())
+}
+
+// Check whether d is the last business day of its month
+func (c *Calendar) isMonthEnd(d time.Time) bool {
+	return c.IsBusinessDay(d) && c.AddBusinessDays(d, 1).Month() != d.Month()
+}
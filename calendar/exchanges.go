@@ -0,0 +1,197 @@
+package calendar
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Hong Kong Exchanges holidays. Lunar calendar holidays are listed for the published years, and
+// schedules past them are rejected.
+var HKEX = &Calendar{
+	Name: "HKEX",
+	Rules: []Rule{
+		Fixed{Month: time.January, Day: 1, Observance: SundayToMonday},
+		Easter{Offset: -2},
+		Easter{Offset: 1},
+		Fixed{Month: time.May, Day: 1, Observance: SundayToMonday},
+		Fixed{Month: time.July, Day: 1, Observance: SundayToMonday},
+		Fixed{Month: time.October, Day: 1, Observance: SundayToMonday},
+		Fixed{Month: time.December, Day: 25, Observance: christmasHK},
+		Fixed{Month: time.December, Day: 26, Observance: SundayToMonday},
+	},
+	Dates: mustParse(
+		"2023-01-23", "2023-01-24", "2023-01-25", "2023-04-05", "2023-05-26", "2023-06-22", "2023-10-23",
+		"2024-02-12", "2024-02-13", "2024-04-04", "2024-05-15", "2024-06-10", "2024-09-18", "2024-10-11",
+		"2025-01-29", "2025-01-30", "2025-01-31", "2025-04-04", "2025-05-05", "2025-10-07", "2025-10-29",
+		"2026-02-17", "2026-02-18", "2026-02-19", "2026-04-07", "2026-05-25", "2026-06-19", "2026-10-19",
+	),
+	Through: 2026,
+}
+
+// Tokyo Stock Exchange holidays, including the year-end closure. A holiday falling on a Sunday
+// is observed on the next weekday that is not a holiday.
+var TSE = New("TSE",
+	Fixed{Month: time.January, Day: 1},
+	Fixed{Month: time.January, Day: 2},
+	Fixed{Month: time.January, Day: 3},
+	NthWeekday{Month: time.January, Weekday: time.Monday, N: 2},
+	Fixed{Month: time.February, Day: 11, Observance: SundayToMonday},
+	Fixed{Month: time.February, Day: 23, Observance: SundayToMonday, From: 2020},
+	Func(vernalEquinox),
+	Fixed{Month: time.April, Day: 29, Observance: SundayToMonday},
+	Fixed{Month: time.May, Day: 3, Observance: goldenWeek},
+	Fixed{Month: time.May, Day: 4, Observance: goldenWeek},
+	Fixed{Month: time.May, Day: 5, Observance: goldenWeek},
+	NthWeekday{Month: time.July, Weekday: time.Monday, N: 3},
+	Fixed{Month: time.August, Day: 11, Observance: SundayToMonday, From: 2016},
+	NthWeekday{Month: time.September, Weekday: time.Monday, N: 3},
+	Func(autumnalEquinox),
+	Func(citizensHoliday),
+	NthWeekday{Month: time.October, Weekday: time.Monday, N: 2},
+	Fixed{Month: time.November, Day: 3, Observance: SundayToMonday},
+	Fixed{Month: time.November, Day: 23, Observance: SundayToMonday},
+	Fixed{Month: time.December, Day: 31},
+)
+
+// Deutsche Boerse Xetra holidays
+var XETRA = New("XETRA",
+	Fixed{Month: time.January, Day: 1},
+	Easter{Offset: -2},
+	Easter{Offset: 1},
+	Fixed{Month: time.May, Day: 1},
+	Fixed{Month: time.December, Day: 24},
+	Fixed{Month: time.December, Day: 25},
+	Fixed{Month: time.December, Day: 26},
+	Fixed{Month: time.December, Day: 31},
+)
+
+// Singapore Exchange holidays. Lunar calendar holidays are listed for the published years, and
+// schedules past them are rejected.
+var SGX = &Calendar{
+	Name: "SGX",
+	Rules: []Rule{
+		Fixed{Month: time.January, Day: 1, Observance: SundayToMonday},
+		Easter{Offset: -2},
+		Fixed{Month: time.May, Day: 1, Observance: SundayToMonday},
+		Fixed{Month: time.August, Day: 9, Observance: SundayToMonday},
+		Fixed{Month: time.December, Day: 25, Observance: SundayToMonday},
+	},
+	Dates: mustParse(
+		"2023-01-23", "2023-01-24", "2023-06-02", "2023-06-29", "2023-11-13",
+		"2024-02-12", "2024-04-10", "2024-05-22", "2024-06-17", "2024-10-31",
+		"2025-01-29", "2025-01-30", "2025-03-31", "2025-05-12", "2025-10-20",
+		"2026-02-17", "2026-02-18", "2026-05-27", "2026-06-01", "2026-11-09",
+	),
+	Through: 2026,
+}
+
+var (
+	mu        sync.RWMutex
+	exchanges = map[string]*Calendar{
+		NYSE.Name:  NYSE,
+		HKEX.Name:  HKEX,
+		TSE.Name:   TSE,
+		XETRA.Name: XETRA,
+		SGX.Name:   SGX,
+	}
+)
+
+// Name of the calendar of stocks without an exchange calendar
+const DefaultName = "NYSE"
+
+// The registered default calendar, which holidays uploaded by ops may have replaced
+func Default() *Calendar {
+	c, err := Get(DefaultName)
+	if err != nil {
+		return NYSE
+	}
+	return c
+}
+
+// Look up an exchange calendar by name
+func Get(name string) (*Calendar, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	c, ok := exchanges[strings.ToUpper(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown calendar: %s", name)
+	}
+	return c, nil
+}
+
+// Names of the exchange calendars
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	out := make([]string, 0, len(exchanges))
+	for k := range exchanges {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Add a calendar to the registry, replacing any calendar of the same name and the joint calendars
+// cached from it
+func Register(c *Calendar) {
+	mu.Lock()
+	defer mu.Unlock()
+	key := strings.ToUpper(c.Name)
+	if old, ok := exchanges[key]; ok && old != c {
+		forgetJoined(old)
+	}
+	exchanges[key] = c
+}
+
+// Christmas Day falling on a Sunday is observed after Boxing Day
+func christmasHK(d time.Time) time.Time {
+	if d.Weekday() == time.Sunday {
+		return d.AddDate(0, 0, 2)
+	}
+	return d
+}
+
+// A Golden Week holiday falling on a Sunday is observed on the first day after the week
+func goldenWeek(d time.Time) time.Time {
+	if d.Weekday() == time.Sunday {
+		return date(d.Year(), time.May, 6)
+	}
+	return d
+}
+
+// Japanese equinox days for 1980 to 2099
+func vernalEquinox(year int) (time.Time, bool) {
+	day := int(math.Floor(20.8431+0.242194*float64(year-1980))) - (year-1980)/4
+	return SundayToMonday(date(year, time.March, day)), true
+}
+
+func autumnalEquinox(year int) (time.Time, bool) {
+	day := int(math.Floor(23.2488+0.242194*float64(year-1980))) - (year-1980)/4
+	return SundayToMonday(date(year, time.September, day)), true
+}
+
+// A day between Respect for the Aged Day and the autumnal equinox is also a holiday
+func citizensHoliday(year int) (time.Time, bool) {
+	aged, _ := NthWeekday{Month: time.September, Weekday: time.Monday, N: 3}.Date(year)
+	equinox, _ := autumnalEquinox(year)
+	if equinox.Sub(aged) == 48*time.Hour {
+		return aged.AddDate(0, 0, 1), true
+	}
+	return time.Time{}, false
+}
+
+func mustParse(s ...string) []time.Time {
+	out := make([]time.Time, len(s))
+	for i, v := range s {
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			panic(err)
+		}
+		out[i] = d
+	}
+	return out
+}
@@ -0,0 +1,173 @@
+package calendar
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Date layout of listed holidays
+const DateLayout = "2006-01-02"
+
+// A holiday calendar file in JSON format
+type calendarFile struct {
+	Name     string   `json:"name"`
+	Holidays []string `json:"holidays"`
+}
+
+// Create a calendar from a list of holidays in DateLayout, covering the years through the last
+// holiday
+func FromDates(name string, dates []string) (*Calendar, error) {
+	c := &Calendar{Name: strings.ToUpper(name)}
+	for _, v := range dates {
+		d, err := time.Parse(DateLayout, v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid holiday %q: %w", v, err)
+		}
+		c.Dates = append(c.Dates, d)
+	}
+	c.Through = lastYear(c.Dates)
+	return c, nil
+}
+
+// Year of the latest date
+func lastYear(dates []time.Time) int {
+	out := 0
+	for _, d := range dates {
+		if d.Year() > out {
+			out = d.Year()
+		}
+	}
+	return out
+}
+
+// Read a calendar from JSON of the form {"name": "LSE", "holidays": ["2024-01-01", ...]}
+func ParseJSON(r io.Reader) (*Calendar, error) {
+	var f calendarFile
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return nil, err
+	}
+	if f.Name == "" {
+		return nil, errors.New("calendar name is required")
+	}
+	return FromDates(f.Name, f.Holidays)
+}
+
+// Read a calendar from the all-day events of an iCalendar file, named after its X-WR-CALNAME
+// when name is empty, covering the years through the last event. Multi-day events mark every day
+// up to their end date as a holiday. Recurring events are not supported.
+func ParseICS(name string, r io.Reader) (*Calendar, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Calendar{Name: strings.ToUpper(name)}
+	var start, end time.Time
+	for _, line := range lines {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		prop, _, _ := strings.Cut(key, ";")
+		switch strings.ToUpper(prop) {
+		case "BEGIN":
+			start, end = time.Time{}, time.Time{}
+		case "X-WR-CALNAME":
+			if c.Name == "" {
+				c.Name = strings.ToUpper(strings.TrimSpace(value))
+			}
+		case "RRULE":
+			return nil, errors.New("recurring events are not supported")
+		case "DTSTART":
+			if start, err = icsDate(value); err != nil {
+				return nil, err
+			}
+		case "DTEND":
+			if end, err = icsDate(value); err != nil {
+				return nil, err
+			}
+		case "END":
+			if strings.ToUpper(value) != "VEVENT" || start.IsZero() {
+				continue
+			}
+			c.Dates = append(c.Dates, start)
+			for d := start.AddDate(0, 0, 1); d.Before(end); d = d.AddDate(0, 0, 1) {
+				c.Dates = append(c.Dates, d)
+			}
+		}
+	}
+	if c.Name == "" {
+		return nil, errors.New("calendar name is required")
+	}
+	c.Through = lastYear(c.Dates)
+	return c, nil
+}
+
+// Read a calendar from a .json or .ics file. ICS calendars are named after the file.
+func Load(path string) (*Calendar, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ext := filepath.Ext(path)
+	switch strings.ToLower(ext) {
+	case ".json":
+		return ParseJSON(f)
+	case ".ics":
+		return ParseICS(strings.TrimSuffix(filepath.Base(path), ext), f)
+	}
+	return nil, fmt.Errorf("unsupported calendar file: %s", path)
+}
+
+// Read the .json and .ics calendars in a directory
+func LoadDir(dir string) ([]*Calendar, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var out []*Calendar
+	for _, e := range entries {
+		ext := strings.ToLower(filepath.Ext(e.Name()))
+		if e.IsDir() || (ext != ".json" && ext != ".ics") {
+			continue
+		}
+		c, err := Load(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// Read the content lines of an iCalendar file, joining folded lines
+func unfold(r io.Reader) ([]string, error) {
+	var out []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(out) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			out[len(out)-1] += line[1:]
+			continue
+		}
+		out = append(out, line)
+	}
+	return out, scanner.Err()
+}
+
+// Parse the date of an iCalendar DATE or DATE-TIME value
+func icsDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("invalid iCalendar date: %s", value)
+	}
+	return time.Parse("20060102", value[:8])
+}
@@ -0,0 +1,78 @@
+package calendar
+
+import (
+	"math/bits"
+	"time"
+)
+
+// Years covered by the precomputed business day index. Dates outside them are checked against
+// the holiday rules.
+const (
+	IndexFrom = 1990
+	IndexTo   = 2100
+)
+
+var indexEpoch = date(IndexFrom, time.January, 1)
+
+// Business days of a calendar precomputed as a bitset over the size days since indexEpoch, with
+// the number of business days before each word of the bitset and the sorted business day numbers.
+type index struct {
+	size  int
+	bits  []uint64
+	ranks []int32
+	days  []int32
+}
+
+// Build the index of a calendar by evaluating its holiday rules once per year
+func newIndex(c *Calendar) *index {
+	n := dayNumber(date(IndexTo+1, time.January, 1))
+	idx := &index{size: n, bits: make([]uint64, (n+63)/64)}
+
+	for i := 0; i < n; i++ {
+		if isWeekday(indexEpoch.AddDate(0, 0, i)) {
+			idx.bits[i/64] |= 1 << (i % 64)
+		}
+	}
+	for y := IndexFrom; y <= IndexTo; y++ {
+		for _, h := range c.Holidays(y) {
+			i := dayNumber(h)
+			idx.bits[i/64] &^= 1 << (i % 64)
+		}
+	}
+
+	idx.ranks = make([]int32, len(idx.bits))
+	count := 0
+	for w, b := range idx.bits {
+		idx.ranks[w] = int32(count)
+		count += bits.OnesCount64(b)
+	}
+	idx.days = make([]int32, 0, count)
+	for i := 0; i < n; i++ {
+		if idx.isSet(i) {
+			idx.days = append(idx.days, int32(i))
+		}
+	}
+	return idx
+}
+
+// Check whether day i is in the index
+func (idx *index) contains(i int) bool {
+	return i >= 0 && i < idx.size
+}
+
+// Check whether day i is a business day
+func (idx *index) isSet(i int) bool {
+	return idx.bits[i/64]&(1<<(i%64)) != 0
+}
+
+// Number of business days before day i
+func (idx *index) rank(i int) int {
+	w := i / 64
+	return int(idx.ranks[w]) + bits.OnesCount64(idx.bits[w]&(1<<(i%64)-1))
+}
+
+// Days between indexEpoch and the date of d, ignoring its time and location
+func dayNumber(d time.Time) int {
+	y, m, day := d.Date()
+	return int((date(y, m, day).Unix() - indexEpoch.Unix()) / 86400)
+}
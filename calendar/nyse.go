@@ -0,0 +1,18 @@
+package calendar
+
+import "time"
+
+// New York Stock Exchange holidays. New Year's Day falling on a Saturday is not observed on
+// the Friday before.
+var NYSE = New("NYSE",
+	Fixed{Month: time.January, Day: 1, Observance: SundayToMonday},
+	NthWeekday{Month: time.January, Weekday: time.Monday, N: 3, From: 1998},
+	NthWeekday{Month: time.February, Weekday: time.Monday, N: 3},
+	Easter{Offset: -2},
+	NthWeekday{Month: time.May, Weekday: time.Monday, N: -1},
+	Fixed{Month: time.June, Day: 19, Observance: NearestWeekday, From: 2022},
+	Fixed{Month: time.July, Day: 4, Observance: NearestWeekday},
+	NthWeekday{Month: time.September, Weekday: time.Monday, N: 1},
+	NthWeekday{Month: time.November, Weekday: time.Thursday, N: 4},
+	Fixed{Month: time.December, Day: 25, Observance: NearestWeekday},
+)
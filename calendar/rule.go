@@ -0,0 +1,127 @@
+package calendar
+
+import "time"
+
+// A Rule generates the date a holiday is observed on in a given year, if it is observed that year.
+type Rule interface {
+	Date(year int) (time.Time, bool)
+}
+
+// An Observance moves a holiday falling on a weekend to the day it is observed on.
+type Observance func(time.Time) time.Time
+
+// Move a Saturday holiday to the Friday before and a Sunday holiday to the Monday after
+func NearestWeekday(d time.Time) time.Time {
+	switch d.Weekday() {
+	case time.Saturday:
+		return d.AddDate(0, 0, -1)
+	case time.Sunday:
+		return d.AddDate(0, 0, 1)
+	}
+	return d
+}
+
+// Move a Sunday holiday to the Monday after
+func SundayToMonday(d time.Time) time.Time {
+	if d.Weekday() == time.Sunday {
+		return d.AddDate(0, 0, 1)
+	}
+	return d
+}
+
+// Move a weekend holiday to the Monday after
+func NextMonday(d time.Time) time.Time {
+	switch d.Weekday() {
+	case time.Saturday:
+		return d.AddDate(0, 0, 2)
+	case time.Sunday:
+		return d.AddDate(0, 0, 1)
+	}
+	return d
+}
+
+// A holiday on a fixed day of the year. From and To bound the years the holiday is observed,
+// zero for no bound.
+type Fixed struct {
+	Month      time.Month
+	Day        int
+	Observance Observance
+	From, To   int
+}
+
+func (r Fixed) Date(year int) (time.Time, bool) {
+	if !inForce(year, r.From, r.To) {
+		return time.Time{}, false
+	}
+	d := date(year, r.Month, r.Day)
+	if r.Observance != nil {
+		d = r.Observance(d)
+	}
+	return d, true
+}
+
+// A holiday on the n-th weekday of a month, counted from the end of the month for negative n.
+type NthWeekday struct {
+	Month    time.Month
+	Weekday  time.Weekday
+	N        int
+	From, To int
+}
+
+func (r NthWeekday) Date(year int) (time.Time, bool) {
+	if !inForce(year, r.From, r.To) || r.N == 0 {
+		return time.Time{}, false
+	}
+	if r.N > 0 {
+		d := date(year, r.Month, 1)
+		shift := (int(r.Weekday) - int(d.Weekday()) + 7) % 7
+		return d.AddDate(0, 0, shift+7*(r.N-1)), true
+	}
+	d := date(year, r.Month+1, 0)
+	shift := (int(d.Weekday()) - int(r.Weekday) + 7) % 7
+	return d.AddDate(0, 0, -shift+7*(r.N+1)), true
+}
+
+// A holiday offset by a number of days from Easter Sunday, such as Good Friday at -2.
+type Easter struct {
+	Offset   int
+	From, To int
+}
+
+func (r Easter) Date(year int) (time.Time, bool) {
+	if !inForce(year, r.From, r.To) {
+		return time.Time{}, false
+	}
+	return EasterSunday(year).AddDate(0, 0, r.Offset), true
+}
+
+// A holiday computed by a function of the year
+type Func func(year int) (time.Time, bool)
+
+func (f Func) Date(year int) (time.Time, bool) {
+	return f(year)
+}
+
+// Date of Easter Sunday in the Gregorian calendar
+func EasterSunday(year int) time.Time {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return date(year, time.Month(month), day)
+}
+
+func inForce(year, from, to int) bool {
+	return (from == 0 || year >= from) && (to == 0 || year <= to)
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
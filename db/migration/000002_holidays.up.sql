@@ -0,0 +1,5 @@
+CREATE TABLE "holidays" (
+  "calendar" varchar NOT NULL,
+  "date" varchar NOT NULL,
+  PRIMARY KEY ("calendar", "date")
+);
@@ -0,0 +1,6 @@
+CREATE TABLE "dailycloses" (
+  "date" varchar NOT NULL,
+  "ticker" varchar NOT NULL,
+  "close" float(53) NOT NULL,
+  PRIMARY KEY ("date", "ticker")
+);
@@ -35,6 +35,20 @@ func (m *MockStore) EXPECT() *MockStoreMockRecorder {
 	return m.recorder
 }
 
+// DeleteHolidays mocks base method.
+func (m *MockStore) DeleteHolidays(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteHolidays", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteHolidays indicates an expected call of DeleteHolidays.
+func (mr *MockStoreMockRecorder) DeleteHolidays(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteHolidays", reflect.TypeOf((*MockStore)(nil).DeleteHolidays), arg0, arg1)
+}
+
 // GetAllCorr mocks base method.
 func (m *MockStore) GetAllCorr(arg0 context.Context) ([]db.Corrpair, error) {
 	m.ctrl.T.Helper()
@@ -96,18 +110,33 @@ func (mr *MockStoreMockRecorder) GetAllStats(arg0 interface{}) *gomock.Call {
 }
 
 // GetBacktestValues mocks base method.
-func (m *MockStore) GetBacktestValues(arg0 context.Context) (db.GetBacktestValuesResult, error) {
+func (m *MockStore) GetBacktestValues(arg0 context.Context, arg1 db.GetBacktestValuesParams) (db.GetBacktestValuesResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetBacktestValues", arg0)
+	ret := m.ctrl.Call(m, "GetBacktestValues", arg0, arg1)
 	ret0, _ := ret[0].(db.GetBacktestValuesResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetBacktestValues indicates an expected call of GetBacktestValues.
-func (mr *MockStoreMockRecorder) GetBacktestValues(arg0 interface{}) *gomock.Call {
+func (mr *MockStoreMockRecorder) GetBacktestValues(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBacktestValues", reflect.TypeOf((*MockStore)(nil).GetBacktestValues), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBacktestValues", reflect.TypeOf((*MockStore)(nil).GetBacktestValues), arg0, arg1)
+}
+
+// GetCloses mocks base method.
+func (m *MockStore) GetCloses(arg0 context.Context, arg1 string) ([]db.Dailyclose, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCloses", arg0, arg1)
+	ret0, _ := ret[0].([]db.Dailyclose)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCloses indicates an expected call of GetCloses.
+func (mr *MockStoreMockRecorder) GetCloses(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCloses", reflect.TypeOf((*MockStore)(nil).GetCloses), arg0, arg1)
 }
 
 // GetCorr mocks base method.
@@ -125,6 +154,51 @@ func (mr *MockStoreMockRecorder) GetCorr(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCorr", reflect.TypeOf((*MockStore)(nil).GetCorr), arg0, arg1)
 }
 
+// GetCorrBetween mocks base method.
+func (m *MockStore) GetCorrBetween(arg0 context.Context, arg1 db.GetCorrBetweenParams) ([]db.Corrpair, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCorrBetween", arg0, arg1)
+	ret0, _ := ret[0].([]db.Corrpair)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCorrBetween indicates an expected call of GetCorrBetween.
+func (mr *MockStoreMockRecorder) GetCorrBetween(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCorrBetween", reflect.TypeOf((*MockStore)(nil).GetCorrBetween), arg0, arg1)
+}
+
+// GetCorrDateAsOf mocks base method.
+func (m *MockStore) GetCorrDateAsOf(arg0 context.Context, arg1 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCorrDateAsOf", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCorrDateAsOf indicates an expected call of GetCorrDateAsOf.
+func (mr *MockStoreMockRecorder) GetCorrDateAsOf(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCorrDateAsOf", reflect.TypeOf((*MockStore)(nil).GetCorrDateAsOf), arg0, arg1)
+}
+
+// GetDateBetween mocks base method.
+func (m *MockStore) GetDateBetween(arg0 context.Context, arg1 db.GetDateBetweenParams) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDateBetween", arg0, arg1)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDateBetween indicates an expected call of GetDateBetween.
+func (mr *MockStoreMockRecorder) GetDateBetween(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDateBetween", reflect.TypeOf((*MockStore)(nil).GetDateBetween), arg0, arg1)
+}
+
 // GetLatestCorrDate mocks base method.
 func (m *MockStore) GetLatestCorrDate(arg0 context.Context) (string, error) {
 	m.ctrl.T.Helper()
@@ -200,6 +274,36 @@ func (mr *MockStoreMockRecorder) GetParam(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetParam", reflect.TypeOf((*MockStore)(nil).GetParam), arg0, arg1)
 }
 
+// GetParamBetween mocks base method.
+func (m *MockStore) GetParamBetween(arg0 context.Context, arg1 db.GetParamBetweenParams) ([]db.Modelparameter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetParamBetween", arg0, arg1)
+	ret0, _ := ret[0].([]db.Modelparameter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetParamBetween indicates an expected call of GetParamBetween.
+func (mr *MockStoreMockRecorder) GetParamBetween(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetParamBetween", reflect.TypeOf((*MockStore)(nil).GetParamBetween), arg0, arg1)
+}
+
+// GetParamDateAsOf mocks base method.
+func (m *MockStore) GetParamDateAsOf(arg0 context.Context, arg1 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetParamDateAsOf", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetParamDateAsOf indicates an expected call of GetParamDateAsOf.
+func (mr *MockStoreMockRecorder) GetParamDateAsOf(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetParamDateAsOf", reflect.TypeOf((*MockStore)(nil).GetParamDateAsOf), arg0, arg1)
+}
+
 // GetStats mocks base method.
 func (m *MockStore) GetStats(arg0 context.Context, arg1 string) ([]db.Statistic, error) {
 	m.ctrl.T.Helper()
@@ -215,6 +319,36 @@ func (mr *MockStoreMockRecorder) GetStats(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStats", reflect.TypeOf((*MockStore)(nil).GetStats), arg0, arg1)
 }
 
+// GetStatsBetween mocks base method.
+func (m *MockStore) GetStatsBetween(arg0 context.Context, arg1 db.GetStatsBetweenParams) ([]db.Statistic, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatsBetween", arg0, arg1)
+	ret0, _ := ret[0].([]db.Statistic)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatsBetween indicates an expected call of GetStatsBetween.
+func (mr *MockStoreMockRecorder) GetStatsBetween(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatsBetween", reflect.TypeOf((*MockStore)(nil).GetStatsBetween), arg0, arg1)
+}
+
+// GetStatsDateAsOf mocks base method.
+func (m *MockStore) GetStatsDateAsOf(arg0 context.Context, arg1 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatsDateAsOf", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatsDateAsOf indicates an expected call of GetStatsDateAsOf.
+func (mr *MockStoreMockRecorder) GetStatsDateAsOf(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatsDateAsOf", reflect.TypeOf((*MockStore)(nil).GetStatsDateAsOf), arg0, arg1)
+}
+
 // GetUser mocks base method.
 func (m *MockStore) GetUser(arg0 context.Context, arg1 string) (db.User, error) {
 	m.ctrl.T.Helper()
@@ -245,6 +379,36 @@ func (mr *MockStoreMockRecorder) GetValues(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetValues", reflect.TypeOf((*MockStore)(nil).GetValues), arg0)
 }
 
+// GetValuesAsOf mocks base method.
+func (m *MockStore) GetValuesAsOf(arg0 context.Context, arg1 string) (db.GetValuesResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetValuesAsOf", arg0, arg1)
+	ret0, _ := ret[0].(db.GetValuesResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetValuesAsOf indicates an expected call of GetValuesAsOf.
+func (mr *MockStoreMockRecorder) GetValuesAsOf(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetValuesAsOf", reflect.TypeOf((*MockStore)(nil).GetValuesAsOf), arg0, arg1)
+}
+
+// InsertClose mocks base method.
+func (m *MockStore) InsertClose(arg0 context.Context, arg1 db.InsertCloseParams) (db.Dailyclose, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertClose", arg0, arg1)
+	ret0, _ := ret[0].(db.Dailyclose)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertClose indicates an expected call of InsertClose.
+func (mr *MockStoreMockRecorder) InsertClose(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertClose", reflect.TypeOf((*MockStore)(nil).InsertClose), arg0, arg1)
+}
+
 // InsertCorr mocks base method.
 func (m *MockStore) InsertCorr(arg0 context.Context, arg1 db.InsertCorrParams) (db.Corrpair, error) {
 	m.ctrl.T.Helper()
@@ -260,6 +424,21 @@ func (mr *MockStoreMockRecorder) InsertCorr(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertCorr", reflect.TypeOf((*MockStore)(nil).InsertCorr), arg0, arg1)
 }
 
+// InsertHoliday mocks base method.
+func (m *MockStore) InsertHoliday(arg0 context.Context, arg1 db.InsertHolidayParams) (db.Holiday, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertHoliday", arg0, arg1)
+	ret0, _ := ret[0].(db.Holiday)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertHoliday indicates an expected call of InsertHoliday.
+func (mr *MockStoreMockRecorder) InsertHoliday(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertHoliday", reflect.TypeOf((*MockStore)(nil).InsertHoliday), arg0, arg1)
+}
+
 // InsertParam mocks base method.
 func (m *MockStore) InsertParam(arg0 context.Context, arg1 db.InsertParamParams) (db.Modelparameter, error) {
 	m.ctrl.T.Helper()
@@ -304,3 +483,32 @@ func (mr *MockStoreMockRecorder) InsertUser(arg0, arg1 interface{}) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertUser", reflect.TypeOf((*MockStore)(nil).InsertUser), arg0, arg1)
 }
+
+// ListHolidays mocks base method.
+func (m *MockStore) ListHolidays(arg0 context.Context) ([]db.Holiday, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListHolidays", arg0)
+	ret0, _ := ret[0].([]db.Holiday)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListHolidays indicates an expected call of ListHolidays.
+func (mr *MockStoreMockRecorder) ListHolidays(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListHolidays", reflect.TypeOf((*MockStore)(nil).ListHolidays), arg0)
+}
+
+// SaveHolidays mocks base method.
+func (m *MockStore) SaveHolidays(arg0 context.Context, arg1 string, arg2 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveHolidays", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveHolidays indicates an expected call of SaveHolidays.
+func (mr *MockStoreMockRecorder) SaveHolidays(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveHolidays", reflect.TypeOf((*MockStore)(nil).SaveHolidays), arg0, arg1, arg2)
+}
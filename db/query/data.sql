@@ -7,6 +7,12 @@ SELECT DISTINCT "date"
 FROM "modelparameters"
 ORDER BY "date" DESC
 LIMIT 1;
+-- name: GetParamDateAsOf :one
+SELECT DISTINCT "date"
+FROM "modelparameters"
+WHERE "date" <= $1
+ORDER BY "date" DESC
+LIMIT 1;
 -- name: InsertParam :one
 INSERT INTO "modelparameters" (
     "date",
@@ -30,6 +36,12 @@ SELECT DISTINCT "date"
 FROM "corrpairs"
 ORDER BY "date" DESC
 LIMIT 1;
+-- name: GetCorrDateAsOf :one
+SELECT DISTINCT "date"
+FROM "corrpairs"
+WHERE "date" <= $1
+ORDER BY "date" DESC
+LIMIT 1;
 -- name: InsertCorr :one
 INSERT INTO "corrpairs" ("date", "x0", "x1", "corr")
 VALUES ($1, $2, $3, $4)
@@ -54,6 +66,12 @@ SELECT DISTINCT "date"
 FROM "statistics"
 ORDER BY "date" DESC
 LIMIT 1;
+-- name: GetStatsDateAsOf :one
+SELECT DISTINCT "date"
+FROM "statistics"
+WHERE "date" <= $1
+ORDER BY "date" DESC
+LIMIT 1;
 -- name: InsertStat :one
 INSERT INTO "statistics" ("date", "ticker", "index", "mean", "fixing")
 VALUES ($1, $2, $3, $4, $5)
@@ -77,4 +95,38 @@ ORDER BY "date",
 -- name: GetAllDate :many
 SELECT DISTINCT "date"
 FROM "modelparameters"
-ORDER BY "date";
\ No newline at end of file
+ORDER BY "date";
+-- name: InsertClose :one
+INSERT INTO "dailycloses" ("date", "ticker", "close")
+VALUES ($1, $2, $3)
+RETURNING *;
+-- name: GetCloses :many
+SELECT *
+FROM "dailycloses"
+WHERE "date" >= $1
+ORDER BY "ticker",
+  "date";
+-- name: GetParamBetween :many
+SELECT *
+FROM "modelparameters"
+WHERE "date" BETWEEN sqlc.arg(start) AND sqlc.arg(end)
+ORDER BY "date",
+  "ticker";
+-- name: GetStatsBetween :many
+SELECT *
+FROM "statistics"
+WHERE "date" BETWEEN sqlc.arg(start) AND sqlc.arg(end)
+ORDER BY "date",
+  "ticker";
+-- name: GetCorrBetween :many
+SELECT *
+FROM "corrpairs"
+WHERE "date" BETWEEN sqlc.arg(start) AND sqlc.arg(end)
+ORDER BY "date",
+  "x0",
+  "x1";
+-- name: GetDateBetween :many
+SELECT DISTINCT "date"
+FROM "modelparameters"
+WHERE "date" BETWEEN sqlc.arg(start) AND sqlc.arg(end)
+ORDER BY "date";
@@ -0,0 +1,12 @@
+-- name: InsertHoliday :one
+INSERT INTO "holidays" ("calendar", "date")
+VALUES ($1, $2)
+RETURNING *;
+-- name: DeleteHolidays :exec
+DELETE FROM "holidays"
+WHERE "calendar" = $1;
+-- name: ListHolidays :many
+SELECT *
+FROM "holidays"
+ORDER BY "calendar",
+  "date";
@@ -9,6 +9,14 @@ type GetValuesResult struct {
 	Stats       []Statistic
 	Corrpair    []Corrpair
 	LatestPrice []GetLatestPriceRow
+	ParamDate   string
+	StatsDate   string
+	CorrDate    string
+}
+
+type GetBacktestValuesParams struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
 }
 
 type GetBacktestValuesResult struct {
@@ -23,29 +31,29 @@ func (store *SQLStore) GetValues(ctx context.Context) (GetValuesResult, error) {
 	err := store.execTx(ctx, func(q *Queries) error {
 		var err error
 
-		paramDate, err := q.GetLatestParamDate(ctx)
+		result.ParamDate, err = q.GetLatestParamDate(ctx)
 		if err != nil {
 			return err
 		}
-		result.Params, err = q.GetParam(ctx, paramDate)
+		result.Params, err = q.GetParam(ctx, result.ParamDate)
 		if err != nil {
 			return err
 		}
 
-		statsDate, err := q.GetLatestStatsDate(ctx)
+		result.StatsDate, err = q.GetLatestStatsDate(ctx)
 		if err != nil {
 			return err
 		}
-		result.Stats, err = q.GetStats(ctx, statsDate)
+		result.Stats, err = q.GetStats(ctx, result.StatsDate)
 		if err != nil {
 			return err
 		}
 
-		corrDate, err := q.GetLatestCorrDate(ctx)
+		result.CorrDate, err = q.GetLatestCorrDate(ctx)
 		if err != nil {
 			return err
 		}
-		result.Corrpair, err = q.GetCorr(ctx, corrDate)
+		result.Corrpair, err = q.GetCorr(ctx, result.CorrDate)
 		if err != nil {
 			return err
 		}
@@ -60,27 +68,72 @@ func (store *SQLStore) GetValues(ctx context.Context) (GetValuesResult, error) {
 	return result, err
 }
 
-func (store *SQLStore) GetBacktestValues(ctx context.Context) (GetBacktestValuesResult, error) {
+// GetValuesAsOf loads the latest parameters, stats and correlations on or before date. The
+// latest prices are the fixings on the stats date.
+func (store *SQLStore) GetValuesAsOf(ctx context.Context, date string) (GetValuesResult, error) {
+	var result GetValuesResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.ParamDate, err = q.GetParamDateAsOf(ctx, date)
+		if err != nil {
+			return err
+		}
+		result.Params, err = q.GetParam(ctx, result.ParamDate)
+		if err != nil {
+			return err
+		}
+
+		result.StatsDate, err = q.GetStatsDateAsOf(ctx, date)
+		if err != nil {
+			return err
+		}
+		result.Stats, err = q.GetStats(ctx, result.StatsDate)
+		if err != nil {
+			return err
+		}
+
+		result.CorrDate, err = q.GetCorrDateAsOf(ctx, date)
+		if err != nil {
+			return err
+		}
+		result.Corrpair, err = q.GetCorr(ctx, result.CorrDate)
+		if err != nil {
+			return err
+		}
+
+		for _, v := range result.Stats {
+			result.LatestPrice = append(result.LatestPrice, GetLatestPriceRow{Ticker: v.Ticker, Fixing: v.Fixing})
+		}
+
+		return err
+	})
+	return result, err
+}
+
+// GetBacktestValues loads the parameters, stats and correlations of the dates between Start and
+// End, both included.
+func (store *SQLStore) GetBacktestValues(ctx context.Context, arg GetBacktestValuesParams) (GetBacktestValuesResult, error) {
 	var result GetBacktestValuesResult
 	err := store.execTx(ctx, func(q *Queries) error {
 		var err error
 
-		result.Params, err = q.GetAllParam(ctx)
+		result.Params, err = q.GetParamBetween(ctx, GetParamBetweenParams(arg))
 		if err != nil {
 			return err
 		}
 
-		result.Stats, err = q.GetAllStats(ctx)
+		result.Stats, err = q.GetStatsBetween(ctx, GetStatsBetweenParams(arg))
 		if err != nil {
 			return err
 		}
 
-		result.Corrpair, err = q.GetAllCorr(ctx)
+		result.Corrpair, err = q.GetCorrBetween(ctx, GetCorrBetweenParams(arg))
 		if err != nil {
 			return err
 		}
 
-		result.Date, err = q.GetAllDate(ctx)
+		result.Date, err = q.GetDateBetween(ctx, GetDateBetweenParams(arg))
 		if err != nil {
 			return err
 		}
@@ -89,3 +142,20 @@ func (store *SQLStore) GetBacktestValues(ctx context.Context) (GetBacktestValues
 	})
 	return result, err
 }
+
+// SaveHolidays replaces the holidays of a calendar.
+func (store *SQLStore) SaveHolidays(ctx context.Context, calendar string, dates []string) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		err := q.DeleteHolidays(ctx, calendar)
+		if err != nil {
+			return err
+		}
+		for _, v := range dates {
+			_, err = q.InsertHoliday(ctx, InsertHolidayParams{Calendar: calendar, Date: v})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -148,6 +148,37 @@ func (q *Queries) GetAllStats(ctx context.Context) ([]Statistic, error) {
 	return items, nil
 }
 
+const getCloses = `-- name: GetCloses :many
+SELECT date, ticker, close
+FROM "dailycloses"
+WHERE "date" >= $1
+ORDER BY "ticker",
+  "date"
+`
+
+func (q *Queries) GetCloses(ctx context.Context, date string) ([]Dailyclose, error) {
+	rows, err := q.db.QueryContext(ctx, getCloses, date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Dailyclose{}
+	for rows.Next() {
+		var i Dailyclose
+		if err := rows.Scan(&i.Date, &i.Ticker, &i.Close); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getCorr = `-- name: GetCorr :many
 SELECT date, x0, x1, corr
 FROM "corrpairs"
@@ -184,6 +215,97 @@ func (q *Queries) GetCorr(ctx context.Context, date string) ([]Corrpair, error)
 	return items, nil
 }
 
+const getCorrBetween = `-- name: GetCorrBetween :many
+SELECT date, x0, x1, corr
+FROM "corrpairs"
+WHERE "date" BETWEEN $1 AND $2
+ORDER BY "date",
+  "x0",
+  "x1"
+`
+
+type GetCorrBetweenParams struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+func (q *Queries) GetCorrBetween(ctx context.Context, arg GetCorrBetweenParams) ([]Corrpair, error) {
+	rows, err := q.db.QueryContext(ctx, getCorrBetween, arg.Start, arg.End)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Corrpair{}
+	for rows.Next() {
+		var i Corrpair
+		if err := rows.Scan(
+			&i.Date,
+			&i.X0,
+			&i.X1,
+			&i.Corr,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCorrDateAsOf = `-- name: GetCorrDateAsOf :one
+SELECT DISTINCT "date"
+FROM "corrpairs"
+WHERE "date" <= $1
+ORDER BY "date" DESC
+LIMIT 1
+`
+
+func (q *Queries) GetCorrDateAsOf(ctx context.Context, date string) (string, error) {
+	row := q.db.QueryRowContext(ctx, getCorrDateAsOf, date)
+	err := row.Scan(&date)
+	return date, err
+}
+
+const getDateBetween = `-- name: GetDateBetween :many
+SELECT DISTINCT "date"
+FROM "modelparameters"
+WHERE "date" BETWEEN $1 AND $2
+ORDER BY "date"
+`
+
+type GetDateBetweenParams struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+func (q *Queries) GetDateBetween(ctx context.Context, arg GetDateBetweenParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getDateBetween, arg.Start, arg.End)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var date string
+		if err := rows.Scan(&date); err != nil {
+			return nil, err
+		}
+		items = append(items, date)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getLatestCorrDate = `-- name: GetLatestCorrDate :one
 SELECT DISTINCT "date"
 FROM "corrpairs"
@@ -304,6 +426,64 @@ func (q *Queries) GetParam(ctx context.Context, date string) ([]Modelparameter,
 	return items, nil
 }
 
+const getParamBetween = `-- name: GetParamBetween :many
+SELECT date, ticker, sigma, alpha, beta, kappa, rho
+FROM "modelparameters"
+WHERE "date" BETWEEN $1 AND $2
+ORDER BY "date",
+  "ticker"
+`
+
+type GetParamBetweenParams struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+func (q *Queries) GetParamBetween(ctx context.Context, arg GetParamBetweenParams) ([]Modelparameter, error) {
+	rows, err := q.db.QueryContext(ctx, getParamBetween, arg.Start, arg.End)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Modelparameter{}
+	for rows.Next() {
+		var i Modelparameter
+		if err := rows.Scan(
+			&i.Date,
+			&i.Ticker,
+			&i.Sigma,
+			&i.Alpha,
+			&i.Beta,
+			&i.Kappa,
+			&i.Rho,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getParamDateAsOf = `-- name: GetParamDateAsOf :one
+SELECT DISTINCT "date"
+FROM "modelparameters"
+WHERE "date" <= $1
+ORDER BY "date" DESC
+LIMIT 1
+`
+
+func (q *Queries) GetParamDateAsOf(ctx context.Context, date string) (string, error) {
+	row := q.db.QueryRowContext(ctx, getParamDateAsOf, date)
+	err := row.Scan(&date)
+	return date, err
+}
+
 const getStats = `-- name: GetStats :many
 SELECT date, ticker, index, mean, fixing
 FROM "statistics"
@@ -339,6 +519,81 @@ func (q *Queries) GetStats(ctx context.Context, date string) ([]Statistic, error
 	return items, nil
 }
 
+const getStatsBetween = `-- name: GetStatsBetween :many
+SELECT date, ticker, index, mean, fixing
+FROM "statistics"
+WHERE "date" BETWEEN $1 AND $2
+ORDER BY "date",
+  "ticker"
+`
+
+type GetStatsBetweenParams struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+func (q *Queries) GetStatsBetween(ctx context.Context, arg GetStatsBetweenParams) ([]Statistic, error) {
+	rows, err := q.db.QueryContext(ctx, getStatsBetween, arg.Start, arg.End)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Statistic{}
+	for rows.Next() {
+		var i Statistic
+		if err := rows.Scan(
+			&i.Date,
+			&i.Ticker,
+			&i.Index,
+			&i.Mean,
+			&i.Fixing,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getStatsDateAsOf = `-- name: GetStatsDateAsOf :one
+SELECT DISTINCT "date"
+FROM "statistics"
+WHERE "date" <= $1
+ORDER BY "date" DESC
+LIMIT 1
+`
+
+func (q *Queries) GetStatsDateAsOf(ctx context.Context, date string) (string, error) {
+	row := q.db.QueryRowContext(ctx, getStatsDateAsOf, date)
+	err := row.Scan(&date)
+	return date, err
+}
+
+const insertClose = `-- name: InsertClose :one
+INSERT INTO "dailycloses" ("date", "ticker", "close")
+VALUES ($1, $2, $3)
+RETURNING date, ticker, close
+`
+
+type InsertCloseParams struct {
+	Date   string  `json:"date"`
+	Ticker string  `json:"ticker"`
+	Close  float64 `json:"close"`
+}
+
+func (q *Queries) InsertClose(ctx context.Context, arg InsertCloseParams) (Dailyclose, error) {
+	row := q.db.QueryRowContext(ctx, insertClose, arg.Date, arg.Ticker, arg.Close)
+	var i Dailyclose
+	err := row.Scan(&i.Date, &i.Ticker, &i.Close)
+	return i, err
+}
+
 const insertCorr = `-- name: InsertCorr :one
 INSERT INTO "corrpairs" ("date", "x0", "x1", "corr")
 VALUES ($1, $2, $3, $4)
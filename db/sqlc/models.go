@@ -13,6 +13,12 @@ type Corrpair struct {
 	Corr float64 `json:"corr"`
 }
 
+type Dailyclose struct {
+	Date   string  `json:"date"`
+	Ticker string  `json:"ticker"`
+	Close  float64 `json:"close"`
+}
+
 type Historicaldatum struct {
 	Date       string  `json:"date"`
 	Ticker     string  `json:"ticker"`
@@ -22,6 +28,11 @@ type Historicaldatum struct {
 	Underlying string  `json:"underlying"`
 }
 
+type Holiday struct {
+	Calendar string `json:"calendar"`
+	Date     string `json:"date"`
+}
+
 type Modelparameter struct {
 	Date   string  `json:"date"`
 	Ticker string  `json:"ticker"`
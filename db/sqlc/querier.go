@@ -9,22 +9,34 @@ import (
 )
 
 type Querier interface {
+	DeleteHolidays(ctx context.Context, calendar string) error
 	GetAllCorr(ctx context.Context) ([]Corrpair, error)
 	GetAllDate(ctx context.Context) ([]string, error)
 	GetAllParam(ctx context.Context) ([]Modelparameter, error)
 	GetAllStats(ctx context.Context) ([]Statistic, error)
+	GetCloses(ctx context.Context, date string) ([]Dailyclose, error)
 	GetCorr(ctx context.Context, date string) ([]Corrpair, error)
+	GetCorrBetween(ctx context.Context, arg GetCorrBetweenParams) ([]Corrpair, error)
+	GetCorrDateAsOf(ctx context.Context, date string) (string, error)
+	GetDateBetween(ctx context.Context, arg GetDateBetweenParams) ([]string, error)
 	GetLatestCorrDate(ctx context.Context) (string, error)
 	GetLatestParamDate(ctx context.Context) (string, error)
 	GetLatestPrice(ctx context.Context) ([]GetLatestPriceRow, error)
 	GetLatestStatsDate(ctx context.Context) (string, error)
 	GetParam(ctx context.Context, date string) ([]Modelparameter, error)
+	GetParamBetween(ctx context.Context, arg GetParamBetweenParams) ([]Modelparameter, error)
+	GetParamDateAsOf(ctx context.Context, date string) (string, error)
 	GetStats(ctx context.Context, date string) ([]Statistic, error)
+	GetStatsBetween(ctx context.Context, arg GetStatsBetweenParams) ([]Statistic, error)
+	GetStatsDateAsOf(ctx context.Context, date string) (string, error)
 	GetUser(ctx context.Context, prefix string) (User, error)
+	InsertClose(ctx context.Context, arg InsertCloseParams) (Dailyclose, error)
 	InsertCorr(ctx context.Context, arg InsertCorrParams) (Corrpair, error)
+	InsertHoliday(ctx context.Context, arg InsertHolidayParams) (Holiday, error)
 	InsertParam(ctx context.Context, arg InsertParamParams) (Modelparameter, error)
 	InsertStat(ctx context.Context, arg InsertStatParams) (Statistic, error)
 	InsertUser(ctx context.Context, arg InsertUserParams) (User, error)
+	ListHolidays(ctx context.Context) ([]Holiday, error)
 }
 
 var _ Querier = (*Queries)(nil)
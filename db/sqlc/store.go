@@ -10,7 +10,9 @@ import (
 type Store interface {
 	Querier
 	GetValues(ctx context.Context) (GetValuesResult, error)
-	GetBacktestValues(ctx context.Context) (GetBacktestValuesResult, error)
+	GetValuesAsOf(ctx context.Context, date string) (GetValuesResult, error)
+	GetBacktestValues(ctx context.Context, arg GetBacktestValuesParams) (GetBacktestValuesResult, error)
+	SaveHolidays(ctx context.Context, calendar string, dates []string) error
 }
 
 // SQLStore defines all functions to execute db queries and transactions
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
 
@@ -21,6 +22,11 @@ func main() {
 	}
 	store := db.NewStore(conn)
 	server := api.NewServer(store)
+	server.SetAdmins(config.AdminPrefixes)
+	err = server.LoadCalendars(context.Background(), config.CalendarDir)
+	if err != nil {
+		log.Fatal("cannot load holiday calendars:", err)
+	}
 	err = server.Start(config.ServerAddress)
 	if err != nil {
 		log.Fatal("cannot start server:", err)
@@ -1,14 +1,18 @@
 package mc
 
 import (
+	"math"
 	"sort"
 	"time"
 )
 
-// A stock is identified by its ticker and its calibrated model
+// A stock is identified by its ticker and its calibrated model. Stocks quoted in a currency other
+// than the settlement currency carry the quanto drift adjustment rho * sigma_S * sigma_FX.
 type Stock struct {
-	Ticker string
-	Model  Model
+	Ticker   string
+	Model    Model
+	Currency string
+	Quanto   float64
 }
 
 type Basket []Stock
@@ -47,10 +51,28 @@ func (b Basket) Path(stocks []string, obsdates []time.Time, pxRatio map[string]f
 	x := make(map[string][]float64)
 	for _, v := range b {
 		x[v.Ticker] = v.Model.Path(pxRatio[v.Ticker], dt, z1[v.Ticker], z2[v.Ticker])
+		if v.Quanto != 0 {
+			t := 0.0
+			for i := range dt {
+				t += dt[i]
+				x[v.Ticker][i+1] *= math.Exp(-v.Quanto * t)
+			}
+		}
 	}
 	return x
 }
 
+// Tag the stocks of a basket with their currency and quanto drift adjustment.
+func (b Basket) SetQuanto(currencies map[string]string, quanto map[string]float64) Basket {
+	out := make(Basket, len(b))
+	for i, v := range b {
+		v.Currency = currencies[v.Ticker]
+		v.Quanto = quanto[v.Ticker]
+		out[i] = v
+	}
+	return out
+}
+
 // Constructor for basket
 func NewBasket(modelsMap map[string]Model) Basket {
 	var b Basket
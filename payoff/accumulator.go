@@ -0,0 +1,114 @@
+package payoff
+
+import (
+	"time"
+
+	"github.com/banachtech/spotted-zebra/mc"
+)
+
+// An accumulator buys the worst performer at the strike on every business day, and the geared
+// quantity on days it fixes below the strike. The contract terminates on the first day the
+// worst-of performance is at or above the knock-out barrier. A decumulator sells at the strike
+// instead, with gearing above the strike and knock-out at or below the barrier.
+//
+// The base daily quantity is one over the number of accumulation days, so that the total base
+// quantity is one unit of initial notional. Shares accumulated over a period are settled on the
+// following settlement date, or on the knock-out date, and valued at the fixing on that date.
+type Accumulator struct {
+	Tickers     []string
+	Basket      Aggregation
+	Strike      float64
+	KO          float64
+	Gearing     float64
+	Decumulator bool
+	Maturity    int
+	SettleFreq  int
+	ObsDates    []time.Time
+	SettleDates []time.Time
+}
+
+var _ Payoff = (*Accumulator)(nil)
+
+// Constructor for Accumulator. A zero gearing is taken to be no gearing.
+func NewAccumulator(stocks []string, k, ko, gearing float64, T, freq int, decumulator bool, m map[string][]time.Time) *Accumulator {
+	if gearing == 0 {
+		gearing = 1.0
+	}
+	settledates, ok := m["cpndates"]
+	if !ok {
+		settledates = m["kodates"]
+	}
+	a := Accumulator{
+		Tickers:     stocks,
+		Strike:      k,
+		KO:          ko,
+		Gearing:     gearing,
+		Decumulator: decumulator,
+		Maturity:    T,
+		SettleFreq:  freq,
+		ObsDates:    m["mcdates"],
+		SettleDates: settledates,
+	}
+	return &a
+}
+
+// Observation dates of the accumulator
+func (a *Accumulator) Dates() []time.Time {
+	return a.ObsDates
+}
+
+// Compute the discounted payout of the accumulator on a basket path
+func (a *Accumulator) Payout(path mc.MCPath) float64 {
+	return PV(a.Cashflows(path), a.ObsDates[0])
+}
+
+// Compute the settlement cashflows of the accumulator on a basket path
+func (a *Accumulator) Cashflows(path mc.MCPath) []Cashflow {
+	var cfs []Cashflow
+	var count int
+	wop := a.Basket.Aggregate(path)
+	q := 1.0 / float64(len(a.ObsDates)-1)
+
+	// Shares accumulated since the last settlement
+	shares := 0.0
+	for i := 1; i < len(a.ObsDates); i++ {
+		t := a.ObsDates[i]
+		if a.isKO(wop[i]) {
+			return append(cfs, a.settle(t, shares, wop[i]), Cashflow{Date: t, Type: KnockOut})
+		}
+		if a.isGeared(wop[i]) {
+			shares += a.Gearing * q
+		} else {
+			shares += q
+		}
+		if count < len(a.SettleDates) && t.Equal(a.SettleDates[count]) {
+			cfs = append(cfs, a.settle(t, shares, wop[i]))
+			shares = 0
+			count++
+		}
+	}
+	return cfs
+}
+
+func (a *Accumulator) isKO(s float64) bool {
+	if a.Decumulator {
+		return s <= a.KO
+	}
+	return s >= a.KO
+}
+
+func (a *Accumulator) isGeared(s float64) bool {
+	if a.Decumulator {
+		return s > a.Strike
+	}
+	return s < a.Strike
+}
+
+// Value of settling accumulated shares at the strike against fixing s
+func (a *Accumulator) settle(t time.Time, shares, s float64) Cashflow {
+	amount := shares * (s - a.Strike)
+	if a.Decumulator {
+		amount = -amount
+	}
+	return Cashflow{Date: t, Type: Accumulation, Amount: amount}
+}
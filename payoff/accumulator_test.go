@@ -0,0 +1,51 @@
+package payoff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/banachtech/spotted-zebra/mc"
+	"github.com/banachtech/spotted-zebra/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccumulatorCashflows(t *testing.T) {
+	tNow, _ := time.Parse(Layout, "2023-01-17")
+	dates, err := util.GenerateDates(tNow, 2, 1)
+	require.NoError(t, err)
+	n := len(dates["mcdates"])
+	q := 1.0 / float64(n-1)
+
+	t.Run("ACCUMULATE_GEARED", func(t *testing.T) {
+		acc := NewAccumulator([]string{"AAPL"}, 0.90, 1.05, 2.0, 2, 1, false, dates)
+		cfs := acc.Cashflows(mc.MCPath{"AAPL": flat(n, 0.85)})
+		require.Len(t, cfs, 2)
+		total := 0.0
+		for _, cf := range cfs {
+			require.Equal(t, Accumulation, cf.Type)
+			total += cf.Amount
+		}
+		require.InDelta(t, 2.0*(0.85-0.90), total, 1e-12)
+	})
+
+	t.Run("ACCUMULATE_KNOCK_OUT", func(t *testing.T) {
+		acc := NewAccumulator([]string{"AAPL"}, 0.90, 1.05, 2.0, 2, 1, false, dates)
+		path := flat(n, 1.0)
+		path[3] = 1.06
+		cfs := acc.Cashflows(mc.MCPath{"AAPL": path})
+		require.Len(t, cfs, 2)
+		require.Equal(t, KnockOut, cfs[1].Type)
+		require.True(t, cfs[0].Date.Equal(dates["mcdates"][3]))
+		require.InDelta(t, 2*q*(1.06-0.90), cfs[0].Amount, 1e-12)
+	})
+
+	t.Run("DECUMULATE", func(t *testing.T) {
+		dec := NewAccumulator([]string{"AAPL"}, 1.10, 0.95, 0, 2, 1, true, dates)
+		cfs := dec.Cashflows(mc.MCPath{"AAPL": flat(n, 1.0)})
+		total := 0.0
+		for _, cf := range cfs {
+			total += cf.Amount
+		}
+		require.InDelta(t, 1.10-1.0, total, 1e-12)
+	})
+}
@@ -0,0 +1,51 @@
+package payoff
+
+import (
+	"math"
+
+	"github.com/banachtech/spotted-zebra/mc"
+)
+
+// Knock-in barrier monitoring modes
+const (
+	KIDaily      = "daily"
+	KIContinuous = "continuous"
+	KIEuropean   = "european"
+)
+
+// Probability that a lognormal path between two observations s0 and s1, both above the barrier b,
+// crosses below the barrier in between. Uses the Brownian bridge crossing probability of the log
+// price with volatility vol over a time step dt in years.
+func crossProbability(s0, s1, b, vol, dt float64) float64 {
+	if s0 <= b || s1 <= b {
+		return 1.0
+	}
+	if vol <= 0 || dt <= 0 {
+		return 0.0
+	}
+	return math.Exp(-2.0 * math.Log(s0/b) * math.Log(s1/b) / (vol * vol * dt))
+}
+
+// Probability that a continuously monitored down barrier b is crossed by the aggregated basket
+// performance. For worst-of baskets each underlying is bridged with its own volatility and the
+// crossings are assumed independent given the path. Other baskets bridge the aggregated path
+// with the average volatility.
+func (a Aggregation) crossProbability(path mc.MCPath, perf []float64, b float64, vols map[string]float64, dt []float64) float64 {
+	noCross := 1.0
+	if a.Mode == "" || a.Mode == WorstOf {
+		for k, v := range path {
+			for i := range dt {
+				noCross *= 1.0 - crossProbability(v[i], v[i+1], b, vols[k], dt[i])
+			}
+		}
+		return 1.0 - noCross
+	}
+	vol := 0.0
+	for k := range path {
+		vol += vols[k] / float64(len(path))
+	}
+	for i := range dt {
+		noCross *= 1.0 - crossProbability(perf[i], perf[i+1], b, vol, dt[i])
+	}
+	return 1.0 - noCross
+}
@@ -0,0 +1,67 @@
+package payoff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/banachtech/spotted-zebra/mc"
+	"github.com/banachtech/spotted-zebra/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCrossProbability(t *testing.T) {
+	require.Equal(t, 1.0, crossProbability(0.69, 0.80, 0.70, 0.3, 1/365.0))
+	require.Equal(t, 0.0, crossProbability(0.80, 0.80, 0.70, 0, 1/365.0))
+
+	near := crossProbability(0.71, 0.71, 0.70, 0.3, 1/365.0)
+	far := crossProbability(0.90, 0.90, 0.70, 0.3, 1/365.0)
+	require.Greater(t, near, far)
+	require.Less(t, near, 1.0)
+	require.Greater(t, near, 0.0)
+}
+
+func TestFCNContinuousKI(t *testing.T) {
+	tNow, _ := time.Parse(Layout, "2023-01-17")
+	dates, err := util.GenerateDates(tNow, 3, 1)
+	require.NoError(t, err)
+	n := len(dates["mcdates"])
+
+	// Worst-of closes just above the KI barrier every day and finishes below the strike
+	path := mc.MCPath{"AAPL": make([]float64, n), "TSLA": make([]float64, n)}
+	for i := range path["AAPL"] {
+		path["AAPL"][i] = 0.95
+		path["TSLA"][i] = 0.74
+	}
+
+	type testCases struct {
+		name string
+		mode string
+		vols map[string]float64
+	}
+
+	for _, test := range []testCases{
+		{name: "DAILY", mode: KIDaily},
+		{name: "EUROPEAN", mode: KIEuropean},
+		{name: "CONTINUOUS", mode: KIContinuous, vols: map[string]float64{"AAPL": 0.3, "TSLA": 0.5}},
+		{name: "MISSING_VOLS", mode: KIContinuous, vols: map[string]float64{"AAPL": 0.3}},
+		{name: "UNSUPPORTED", mode: "weekly"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fcn := NewFCN([]string{"AAPL", "TSLA"}, 0.80, 0, 0, 0, 1.05, 0.70, 0.80, 3, 1, false, dates)
+			err := fcn.SetKIMonitoring(test.mode, test.vols)
+			if test.name == "MISSING_VOLS" || test.name == "UNSUPPORTED" {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			cfs := fcn.Cashflows(path)
+			redemption := cfs[len(cfs)-1].Amount
+			if test.mode == KIContinuous {
+				require.Less(t, redemption, 1.0)
+				require.Greater(t, redemption, 0.74/0.80)
+			} else {
+				require.Equal(t, 1.0, redemption)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package payoff
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/banachtech/spotted-zebra/mc"
+)
+
+// Basket aggregation modes
+const (
+	WorstOf  = "worst_of"
+	BestOf   = "best_of"
+	Average  = "average"
+	Weighted = "weighted"
+	Ranked   = "ranked"
+)
+
+// Aggregation reduces a basket path of price ratios to the single performance path that a
+// product observes. The zero value is worst-of.
+type Aggregation struct {
+	Mode    string
+	Weights map[string]float64
+	Rank    int
+}
+
+// Constructor for Aggregation. Weights are normalised to sum to one, and rank is the k-th worst
+// performer counting from one.
+func NewAggregation(mode string, weights map[string]float64, rank int, stocks []string) (Aggregation, error) {
+	switch mode {
+	case "", WorstOf, BestOf, Average:
+		return Aggregation{Mode: mode}, nil
+	case Weighted:
+		w := map[string]float64{}
+		sum := 0.0
+		for k, v := range weights {
+			if v < 0 {
+				return Aggregation{}, fmt.Errorf("negative weight for %s", k)
+			}
+			w[strings.ToUpper(k)] = v
+			sum += v
+		}
+		if sum == 0 {
+			return Aggregation{}, fmt.Errorf("weights must sum to more than 0")
+		}
+		for _, v := range stocks {
+			if _, ok := w[v]; !ok {
+				return Aggregation{}, fmt.Errorf("missing weight for %s", v)
+			}
+		}
+		if len(w) != len(stocks) {
+			return Aggregation{}, fmt.Errorf("weights given for %d stocks, expected %d", len(w), len(stocks))
+		}
+		for k := range w {
+			w[k] /= sum
+		}
+		return Aggregation{Mode: mode, Weights: w}, nil
+	case Ranked:
+		if rank < 1 || rank > len(stocks) {
+			return Aggregation{}, fmt.Errorf("rank must be between 1 and %d", len(stocks))
+		}
+		return Aggregation{Mode: mode, Rank: rank}, nil
+	default:
+		return Aggregation{}, fmt.Errorf("unsupported basket type: %s", mode)
+	}
+}
+
+// Reduce a basket path to a performance path
+func (a Aggregation) Aggregate(path mc.MCPath) []float64 {
+	switch a.Mode {
+	case BestOf:
+		return reduce(path, func(p []float64) float64 { return p[len(p)-1] })
+	case Average:
+		return reduce(path, func(p []float64) float64 {
+			sum := 0.0
+			for _, v := range p {
+				sum += v
+			}
+			return sum / float64(len(p))
+		})
+	case Weighted:
+		var out []float64
+		for k, v := range path {
+			if out == nil {
+				out = make([]float64, len(v))
+			}
+			for i := range out {
+				out[i] += a.Weights[k] * v[i]
+			}
+		}
+		return out
+	case Ranked:
+		return reduce(path, func(p []float64) float64 { return p[a.Rank-1] })
+	default:
+		return worstOf(path)
+	}
+}
+
+// Reduce a basket path by applying f to the sorted performances at each observation date.
+func reduce(path mc.MCPath, f func([]float64) float64) []float64 {
+	var n int
+	for _, v := range path {
+		n = len(v)
+	}
+	out := make([]float64, n)
+	p := make([]float64, 0, len(path))
+	for i := range out {
+		p = p[:0]
+		for _, v := range path {
+			p = append(p, v[i])
+		}
+		sort.Float64s(p)
+		out[i] = f(p)
+	}
+	return out
+}
+
+// Reduce a basket path to the worst-of performance path.
+func worstOf(path mc.MCPath) []float64 {
+	var out []float64
+	for _, v := range path {
+		if out == nil {
+			out = make([]float64, len(v))
+			copy(out, v)
+			continue
+		}
+		for i := range out {
+			out[i] = math.Min(out[i], v[i])
+		}
+	}
+	return out
+}
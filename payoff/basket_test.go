@@ -0,0 +1,51 @@
+package payoff
+
+import (
+	"testing"
+
+	"github.com/banachtech/spotted-zebra/mc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregation(t *testing.T) {
+	stocks := []string{"AAPL", "AVGO", "TSLA"}
+	path := mc.MCPath{
+		"AAPL": {1.0, 0.9},
+		"AVGO": {1.0, 1.2},
+		"TSLA": {1.0, 0.6},
+	}
+
+	type testCases struct {
+		name    string
+		mode    string
+		weights map[string]float64
+		rank    int
+		want    float64
+		isErr   bool
+	}
+
+	for _, test := range []testCases{
+		{name: "DEFAULT", mode: "", want: 0.6},
+		{name: "WORST_OF", mode: WorstOf, want: 0.6},
+		{name: "BEST_OF", mode: BestOf, want: 1.2},
+		{name: "AVERAGE", mode: Average, want: 0.9},
+		{name: "WEIGHTED", mode: Weighted, weights: map[string]float64{"aapl": 2, "AVGO": 1, "TSLA": 1}, want: 0.9},
+		{name: "RANKED", mode: Ranked, rank: 2, want: 0.9},
+		{name: "MISSING_WEIGHT", mode: Weighted, weights: map[string]float64{"AAPL": 1, "AVGO": 1}, isErr: true},
+		{name: "RANK_OUT_OF_RANGE", mode: Ranked, rank: 4, isErr: true},
+		{name: "UNSUPPORTED", mode: "rainbow", isErr: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			agg, err := NewAggregation(test.mode, test.weights, test.rank, stocks)
+			if test.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			perf := agg.Aggregate(path)
+			require.Len(t, perf, 2)
+			require.InDelta(t, 1.0, perf[0], 1e-12)
+			require.InDelta(t, test.want, perf[1], 1e-12)
+		})
+	}
+}
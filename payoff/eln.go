@@ -0,0 +1,49 @@
+package payoff
+
+import (
+	"math"
+	"time"
+
+	"github.com/banachtech/spotted-zebra/mc"
+)
+
+// An equity-linked note is a discount note that redeems at par if the worst-of performance at
+// maturity is at or above the strike, and otherwise delivers the worst performer at the strike,
+// worth S_T / K per unit notional.
+type ELN struct {
+	Tickers  []string
+	Basket   Aggregation
+	Strike   float64
+	Maturity int
+	ObsDates []time.Time
+}
+
+var _ Payoff = (*ELN)(nil)
+
+// Constructor for ELN
+func NewELN(stocks []string, k float64, T int, m map[string][]time.Time) *ELN {
+	e := ELN{
+		Tickers:  stocks,
+		Strike:   k,
+		Maturity: T,
+		ObsDates: m["mcdates"],
+	}
+	return &e
+}
+
+// Observation dates of the ELN
+func (e *ELN) Dates() []time.Time {
+	return e.ObsDates
+}
+
+// Compute the discounted payout of the ELN on a basket path
+func (e *ELN) Payout(path mc.MCPath) float64 {
+	return PV(e.Cashflows(path), e.ObsDates[0])
+}
+
+// Compute the cashflows of the ELN on a basket path
+func (e *ELN) Cashflows(path mc.MCPath) []Cashflow {
+	wop := e.Basket.Aggregate(path)
+	T := len(wop) - 1
+	return []Cashflow{{Date: e.ObsDates[T], Type: Redemption, Amount: math.Min(1.0, wop[T]/e.Strike)}}
+}
@@ -0,0 +1,42 @@
+package payoff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/banachtech/spotted-zebra/mc"
+	"github.com/banachtech/spotted-zebra/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestELNCashflows(t *testing.T) {
+	tNow, _ := time.Parse(Layout, "2023-01-17")
+	dates, err := util.GenerateDates(tNow, 6, 6)
+	require.NoError(t, err)
+	n := len(dates["mcdates"])
+	eln := NewELN([]string{"AAPL", "TSLA"}, 0.90, 6, dates)
+
+	type testCases struct {
+		name       string
+		final      float64
+		redemption float64
+	}
+
+	for _, test := range []testCases{
+		{name: "ABOVE_STRIKE", final: 0.95, redemption: 1.0},
+		{name: "BELOW_STRIKE", final: 0.72, redemption: 0.80},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			path := mc.MCPath{"AAPL": make([]float64, n), "TSLA": make([]float64, n)}
+			for i := range path["AAPL"] {
+				path["AAPL"][i] = 1.0
+				path["TSLA"][i] = 1.0
+			}
+			path["TSLA"][n-1] = test.final
+			cfs := eln.Cashflows(path)
+			require.Len(t, cfs, 1)
+			require.InDelta(t, test.redemption, cfs[0].Amount, 1e-12)
+			require.Less(t, eln.Payout(path), test.redemption)
+		})
+	}
+}
@@ -1,12 +1,16 @@
 package payoff
 
 import (
+	"fmt"
 	"math"
 	"time"
+
+	"github.com/banachtech/spotted-zebra/mc"
 )
 
 type FCN struct {
 	Tickers       []string
+	Basket        Aggregation
 	Strike        float64
 	Coupon        float64
 	BarrierCoupon float64
@@ -14,14 +18,34 @@ type FCN struct {
 	KO            float64
 	KI            float64
 	KC            float64
+	KOSchedule    []float64
+	KCSchedule    []float64
+	FixedCpns     []float64
+	BarrierCpns   []float64
 	Maturity      int
 	CallFreq      int
-	IsEuroKI      bool
+	KIMonitoring  string
+	KIVols        map[string]float64
+	Settlement    string
+	Fixings       map[string]float64
+	Denomination  float64
 	ObsDates      []time.Time
+	CpnDates      []time.Time
 	KODates       []time.Time
 	KIDates       []time.Time
+	Start         int
+	History       History
+}
+
+// Observed history of a note already in its life
+type History struct {
+	KnockedIn bool
+	// Memory coupons missed since the last coupon paid
+	MissedCoupons float64
 }
 
+var _ Payoff = (*FCN)(nil)
+
 type FCNOutput struct {
 	StrikeDate    string             `json:"strike_date"`
 	Tickers       []string           `json:"underlying_tickers"`
@@ -43,11 +67,17 @@ const Layout = "2006-01-02"
 
 func NewFCN(stocks []string, k, cpn, barCpn, fixCpn, ko, ki, kc float64, T, freq int, isEuro bool, m map[string][]time.Time) *FCN {
 	var kidates []time.Time
+	monitoring := KIDaily
 	if isEuro {
 		kidates = []time.Time{m["mcdates"][len(m["mcdates"])-1]}
+		monitoring = KIEuropean
 	} else {
 		kidates = m["mcdates"]
 	}
+	cpndates, ok := m["cpndates"]
+	if !ok {
+		cpndates = m["kodates"]
+	}
 	f := FCN{
 		Tickers:       stocks,
 		Strike:        k,
@@ -59,53 +89,268 @@ func NewFCN(stocks []string, k, cpn, barCpn, fixCpn, ko, ki, kc float64, T, freq
 		KC:            kc,
 		Maturity:      T,
 		CallFreq:      freq,
-		IsEuroKI:      isEuro,
+		KIMonitoring:  monitoring,
 		ObsDates:      m["mcdates"],
+		CpnDates:      cpndates,
 		KODates:       m["kodates"],
 		KIDates:       kidates,
 	}
 	return &f
 }
 
-func (f *FCN) Payout(path []float64) float64 {
-	var count int
-	out := 1.0
-	T := len(path) - 1
+// Remaining observation dates of the FCN from the valuation date
+func (f *FCN) Dates() []time.Time {
+	return f.ObsDates[f.Start:]
+}
+
+// Compute the discounted payout of the FCN on a basket path
+func (f *FCN) Payout(path mc.MCPath) float64 {
+	return PV(f.Cashflows(path), f.Dates()[0])
+}
+
+// Compute the cashflows of the FCN on a basket path. Coupons are paid on coupon dates and the
+// principal, less any knock-in loss, is redeemed on the KO date or at maturity.
+func (f *FCN) Cashflows(path mc.MCPath) []Cashflow {
+	count, nko := f.elapsed()
+	wop := f.Basket.Aggregate(path)
 
 	// Initialise KI flag
-	isKI := false
-	for i, t := range f.ObsDates {
-		factor := 1 / 12.0
-		// Check for KO and redeem if required
-		// Coupon dates coincide with KO dates, so pay coupon if required
-		if t.Equal(f.KODates[count]) {
-			// fmt.Printf("At %v\n", t.Format(Layout))
-			out += factor * f.FixedCoupon
-			// fmt.Printf("fixed coupon: %0.9f\n", factor*f.FixedCoupon)
-			if path[i] > f.KC {
-				out += factor * f.BarrierCoupon
-				// fmt.Printf("barrier coupon: %0.9f\n", factor*f.BarrierCoupon)
+	isKI, cfs := f.pastKnockIn()
+	factor := f.periodFactor()
+	for i, t := range f.Dates() {
+		// Pay coupons on coupon dates, and check for KO and redeem if required on KO dates
+		if count < len(f.CpnDates) && t.Equal(f.CpnDates[count]) {
+			cfs = append(cfs, Cashflow{Date: t, Type: FixedCoupon, Amount: f.fixedCoupon(count, factor)})
+			if wop[i] > f.couponBarrier(count) {
+				cfs = append(cfs, Cashflow{Date: t, Type: BarrierCoupon, Amount: f.barrierCoupon(count, factor)})
 			}
-			if path[i] > f.KO {
-				out += float64(count+1) * factor * f.Coupon
-				// fmt.Printf("knock-out coupon: %0.9f\n", float64(count+1)*factor*f.Coupon)
-				dt := float64(t.Unix()-f.ObsDates[0].Unix()) / float64(60*60*24*365)
-				// dt := t.Sub(f.ObsDates[0]).Seconds()
-				return math.Exp(-0.03*dt) * out
+			if f.isKODate(t, nko) {
+				if wop[i] > f.knockOutBarrier(nko) {
+					cfs = append(cfs, Cashflow{Date: t, Type: AutocallCoupon, Amount: float64(count+1) * factor * f.Coupon})
+					cfs = append(cfs, Cashflow{Date: t, Type: KnockOut})
+					return append(cfs, Cashflow{Date: t, Type: Redemption, Amount: 1.0})
+				}
+				nko++
 			}
 			count++
 		}
 
-		if !f.IsEuroKI && !isKI {
-			if path[i] < f.KI {
+		if f.KIMonitoring != KIEuropean && !isKI {
+			if wop[i] < f.KI {
 				isKI = true
+				cfs = append(cfs, Cashflow{Date: t, Type: KnockIn})
+			}
+		}
+	}
+	return append(cfs, f.redemption(path, wop, isKI)...)
+}
+
+// Set the observed history of a note already in its life and valued on valDate. Only the
+// observation dates from the last one on or before valDate are simulated, and coupons due up to
+// then are taken as settled.
+func (f *FCN) SetHistory(valDate time.Time, h History) error {
+	start := -1
+	for i, t := range f.ObsDates {
+		if t.After(valDate) {
+			break
+		}
+		start = i
+	}
+	if start < 0 {
+		return fmt.Errorf("valuation date %s is before the strike date", valDate.Format(Layout))
+	}
+	if start == len(f.ObsDates)-1 {
+		return fmt.Errorf("note has matured on %s", f.ObsDates[start].Format(Layout))
+	}
+	f.Start = start
+	f.History = h
+	return nil
+}
+
+// Number of coupon and KO dates on or before the valuation date
+func (f *FCN) elapsed() (int, int) {
+	t0 := f.ObsDates[f.Start]
+	var count, nko int
+	for count < len(f.CpnDates) && !f.CpnDates[count].After(t0) {
+		count++
+	}
+	for nko < len(f.KODates) && !f.KODates[nko].After(t0) {
+		nko++
+	}
+	return count, nko
+}
+
+// Initial KI flag of the simulation, with a knock-in event on the valuation date if the note
+// knocked in before it.
+func (f *FCN) pastKnockIn() (bool, []Cashflow) {
+	if !f.History.KnockedIn {
+		return false, nil
+	}
+	return true, []Cashflow{{Date: f.Dates()[0], Type: KnockIn}}
+}
+
+// Check whether t is the next KO date, given nko KO dates have passed
+func (f *FCN) isKODate(t time.Time, nko int) bool {
+	return nko < len(f.KODates) && t.Equal(f.KODates[nko])
+}
+
+// Set per KO date knock-out and per coupon date coupon barrier levels. An empty schedule keeps the flat KO or KC barrier.
+func (f *FCN) SetBarrierSchedule(ko, kc []float64) error {
+	if len(ko) > 0 && len(ko) != len(f.KODates) {
+		return fmt.Errorf("knock-out barrier schedule has %d levels, expected %d", len(ko), len(f.KODates))
+	}
+	if len(kc) > 0 && len(kc) != len(f.CpnDates) {
+		return fmt.Errorf("coupon barrier schedule has %d levels, expected %d", len(kc), len(f.CpnDates))
+	}
+	f.KOSchedule = ko
+	f.KCSchedule = kc
+	return nil
+}
+
+// Knock-out barrier on the i-th KO date
+func (f *FCN) knockOutBarrier(i int) float64 {
+	if len(f.KOSchedule) > 0 {
+		return f.KOSchedule[i]
+	}
+	return f.KO
+}
+
+// Coupon barrier on the i-th coupon date
+func (f *FCN) couponBarrier(i int) float64 {
+	if len(f.KCSchedule) > 0 {
+		return f.KCSchedule[i]
+	}
+	return f.KC
+}
+
+// Set the fixed and barrier coupon amounts paid on each coupon date, per unit notional. An empty
+// schedule keeps the coupon rate accrued over the period.
+func (f *FCN) SetCouponSchedule(fixed, barrier []float64) error {
+	if len(fixed) > 0 && len(fixed) != len(f.CpnDates) {
+		return fmt.Errorf("fixed coupon schedule has %d amounts, expected %d", len(fixed), len(f.CpnDates))
+	}
+	if len(barrier) > 0 && len(barrier) != len(f.CpnDates) {
+		return fmt.Errorf("barrier coupon schedule has %d amounts, expected %d", len(barrier), len(f.CpnDates))
+	}
+	f.FixedCpns = fixed
+	f.BarrierCpns = barrier
+	return nil
+}
+
+// Fixed coupon paid on the i-th coupon date for an accrual factor
+func (f *FCN) fixedCoupon(i int, factor float64) float64 {
+	if len(f.FixedCpns) > 0 {
+		return f.FixedCpns[i]
+	}
+	return factor * f.FixedCoupon
+}
+
+// Accrual factor of an annual coupon rate over one period of the call frequency
+func (f *FCN) periodFactor() float64 {
+	return float64(f.CallFreq) / 12.0
+}
+
+// Barrier coupon paid on the i-th coupon date for an accrual factor
+func (f *FCN) barrierCoupon(i int, factor float64) float64 {
+	if len(f.BarrierCpns) > 0 {
+		return f.BarrierCpns[i]
+	}
+	return factor * f.BarrierCoupon
+}
+
+// Redemption at maturity of a note that was not knocked out. The principal is reduced by the
+// put payoff on the worst-of performance if the note knocked in. With physical settlement a
+// knocked-in note below the strike instead delivers the worst performer. With continuous
+// monitoring a note that did not knock in on a daily close is reduced by the put payoff weighted
+// by the probability of crossing the barrier between closes.
+func (f *FCN) redemption(path mc.MCPath, wop []float64, isKI bool) []Cashflow {
+	var cfs []Cashflow
+	obs := f.Dates()
+	T := len(wop) - 1
+	if f.KIMonitoring == KIEuropean && wop[T] < f.KI {
+		isKI = true
+		cfs = append(cfs, Cashflow{Date: obs[T], Type: KnockIn})
+	}
+	amount := 1.0
+	if isKI {
+		if f.Settlement == Physical && wop[T] < f.Strike {
+			return append(cfs, f.delivery(path)...)
+		}
+		amount += (-1.0 / f.Strike) * math.Max(f.Strike-wop[T], 0)
+	} else if f.KIMonitoring == KIContinuous {
+		dt := make([]float64, T)
+		for i := range dt {
+			dt[i] = YearFrac(obs[i], obs[i+1])
+		}
+		p := f.Basket.crossProbability(path, wop, f.KI, f.KIVols, dt)
+		amount += p * (-1.0 / f.Strike) * math.Max(f.Strike-wop[T], 0)
+	}
+	return append(cfs, Cashflow{Date: obs[T], Type: Redemption, Amount: amount})
+}
+
+// Set the knock-in monitoring mode. Continuous monitoring requires the volatility of each
+// underlying for the Brownian bridge correction between daily closes.
+func (f *FCN) SetKIMonitoring(mode string, vols map[string]float64) error {
+	switch mode {
+	case KIDaily:
+		f.KIDates = f.ObsDates
+	case KIEuropean:
+		f.KIDates = f.ObsDates[len(f.ObsDates)-1:]
+	case KIContinuous:
+		if f.Settlement == Physical {
+			return fmt.Errorf("continuous knock-in monitoring is not supported with physical settlement")
+		}
+		for _, v := range f.Tickers {
+			if vols[v] <= 0 {
+				return fmt.Errorf("missing volatility for %s", v)
 			}
 		}
+		f.KIDates = f.ObsDates
+		f.KIVols = vols
+	default:
+		return fmt.Errorf("unsupported knock-in monitoring: %s", mode)
+	}
+	f.KIMonitoring = mode
+	return nil
+}
+
+// Set physical settlement on knock-in. Fixings are the initial prices of the underlyings and
+// the denomination is the notional of one note, used to round deliveries to whole shares.
+func (f *FCN) SetPhysicalSettlement(fixings map[string]float64, denomination float64) error {
+	if denomination <= 0 {
+		return fmt.Errorf("denomination must be positive for physical settlement")
+	}
+	if f.KIMonitoring == KIContinuous {
+		return fmt.Errorf("continuous knock-in monitoring is not supported with physical settlement")
+	}
+	for _, v := range f.Tickers {
+		if fixings[v] <= 0 {
+			return fmt.Errorf("missing fixing for %s", v)
+		}
+	}
+	f.Settlement = Physical
+	f.Fixings = fixings
+	f.Denomination = denomination
+	return nil
+}
+
+// Deliver the worst performer at the strike. Each note receives the whole number of shares of
+// its denomination converted at the strike, and the fractional share is paid in cash. Amounts
+// and shares are per unit notional.
+func (f *FCN) delivery(path mc.MCPath) []Cashflow {
+	obs := f.Dates()
+	T := len(obs) - 1
+	var worst string
+	for _, v := range f.Tickers {
+		if worst == "" || path[v][T] < path[worst][T] {
+			worst = v
+		}
 	}
-	if isKI || (f.IsEuroKI && path[T] < f.KI) {
-		out += (-1.0 / f.Strike) * math.Max(f.Strike-path[T], 0)
-		// fmt.Printf("knock-in: %0.9f\n", -1.0/f.Strike*math.Max(f.Strike-path[T], 0))
+	px := path[worst][T] * f.Fixings[worst]
+	shares := f.Denomination / (f.Strike * f.Fixings[worst])
+	whole := math.Floor(shares)
+	return []Cashflow{
+		{Date: obs[T], Type: Delivery, Amount: whole * px / f.Denomination, Ticker: worst, Shares: whole / f.Denomination},
+		{Date: obs[T], Type: FractionalCash, Amount: (shares - whole) * px / f.Denomination, Ticker: worst},
 	}
-	dt := float64(f.ObsDates[T].Unix()-f.ObsDates[0].Unix()) / float64(60*60*24*365)
-	return math.Exp(-0.03*dt) * out
 }
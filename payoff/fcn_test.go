@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/banachtech/spotted-zebra/mc"
 	"github.com/banachtech/spotted-zebra/util"
 	"github.com/stretchr/testify/require"
 )
@@ -74,4 +75,245 @@ func TestNewFCN(t *testing.T) {
 			require.NotEmpty(t, fcn)
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestFCNCashflows(t *testing.T) {
+	tNow, _ := time.Parse(Layout, "2023-01-17")
+	dates, err := util.GenerateDates(tNow, 3, 1)
+	require.NoError(t, err)
+	fcn := NewFCN([]string{"AAPL", "TSLA"}, 0.80, 0.12, 0.12, 0.12, 1.05, 0.70, 0.80, 3, 1, false, dates)
+	n := len(dates["mcdates"])
+
+	type testCases struct {
+		name       string
+		path       mc.MCPath
+		redemption float64
+		lastDate   time.Time
+	}
+
+	for _, test := range []testCases{
+		{
+			name:       "KNOCK_OUT",
+			path:       mc.MCPath{"AAPL": flat(n, 1.10), "TSLA": flat(n, 1.20)},
+			redemption: 1.0,
+			lastDate:   dates["kodates"][0],
+		},
+		{
+			name:       "KNOCK_IN",
+			path:       mc.MCPath{"AAPL": flat(n, 1.00), "TSLA": flat(n, 0.60)},
+			redemption: 1.0 - (0.80-0.60)/0.80,
+			lastDate:   dates["mcdates"][n-1],
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			cfs := fcn.Cashflows(test.path)
+			require.NotEmpty(t, cfs)
+			last := cfs[len(cfs)-1]
+			require.Equal(t, Redemption, last.Type)
+			require.InDelta(t, test.redemption, last.Amount, 1e-12)
+			require.True(t, last.Date.Equal(test.lastDate))
+			require.InDelta(t, PV(cfs, tNow), fcn.Payout(test.path), 1e-12)
+		})
+	}
+}
+
+func TestFCNBarrierSchedule(t *testing.T) {
+	tNow, _ := time.Parse(Layout, "2023-01-17")
+	dates, err := util.GenerateDates(tNow, 3, 1)
+	require.NoError(t, err)
+	n := len(dates["mcdates"])
+
+	// Worst-of performance of 0.99 is only above the stepped-down KO barrier on the last KO date
+	path := mc.MCPath{"AAPL": make([]float64, n)}
+	for i := range path["AAPL"] {
+		path["AAPL"][i] = 0.99
+	}
+
+	fcn := NewFCN([]string{"AAPL"}, 0.80, 0.12, 0.12, 0.12, 1.05, 0.70, 0.80, 3, 1, false, dates)
+	err = fcn.SetBarrierSchedule([]float64{1.0, 0.99}, nil)
+	require.Error(t, err)
+
+	err = fcn.SetBarrierSchedule([]float64{1.0, 1.0, 0.98}, []float64{0.80, 1.0, 1.0})
+	require.NoError(t, err)
+
+	var autocall, barrier int
+	for _, cf := range fcn.Cashflows(path) {
+		switch cf.Type {
+		case AutocallCoupon:
+			autocall++
+			require.True(t, cf.Date.Equal(dates["kodates"][2]))
+		case BarrierCoupon:
+			barrier++
+			require.True(t, cf.Date.Equal(dates["kodates"][0]))
+		}
+	}
+	require.Equal(t, 1, autocall)
+	require.Equal(t, 1, barrier)
+}
+
+func TestFCNCouponSchedule(t *testing.T) {
+	tNow, _ := time.Parse(Layout, "2023-01-17")
+	dates, err := util.GenerateDates(tNow, 3, 1)
+	require.NoError(t, err)
+	n := len(dates["mcdates"])
+
+	// Worst-of performance of 0.90 is above the coupon barrier but below the KO barrier
+	path := mc.MCPath{"AAPL": make([]float64, n)}
+	for i := range path["AAPL"] {
+		path["AAPL"][i] = 0.90
+	}
+
+	fcn := NewFCN([]string{"AAPL"}, 0.80, 0.12, 0.12, 0.12, 1.05, 0.70, 0.80, 3, 1, false, dates)
+	err = fcn.SetCouponSchedule([]float64{0.01}, nil)
+	require.Error(t, err)
+
+	err = fcn.SetCouponSchedule([]float64{0.01, 0.02, 0.03}, []float64{0, 0.05, 0})
+	require.NoError(t, err)
+
+	total := 0.0
+	for _, cf := range fcn.Cashflows(path) {
+		if cf.Type == FixedCoupon || cf.Type == BarrierCoupon {
+			total += cf.Amount
+		}
+	}
+	require.InDelta(t, 0.11, total, 1e-12)
+}
+
+func TestFCNHistory(t *testing.T) {
+	tNow, _ := time.Parse(Layout, "2023-01-17")
+	dates, err := util.GenerateDates(tNow, 3, 1)
+	require.NoError(t, err)
+
+	fcn := NewFCN([]string{"AAPL"}, 1.0, 0.12, 0.12, 0.12, 1.05, 0.70, 0.80, 3, 1, false, dates)
+	require.Error(t, fcn.SetHistory(tNow.AddDate(0, 0, -1), History{}))
+	require.Error(t, fcn.SetHistory(dates["mcdates"][len(dates["mcdates"])-1], History{}))
+
+	// Valued a few days after the first coupon date, having knocked in
+	err = fcn.SetHistory(dates["cpndates"][0].AddDate(0, 0, 3), History{KnockedIn: true})
+	require.NoError(t, err)
+	obs := fcn.Dates()
+	require.False(t, obs[0].After(dates["cpndates"][0].AddDate(0, 0, 3)))
+	require.True(t, obs[len(obs)-1].Equal(dates["mcdates"][len(dates["mcdates"])-1]))
+
+	path := mc.MCPath{"AAPL": make([]float64, len(obs))}
+	for i := range path["AAPL"] {
+		path["AAPL"][i] = 0.90
+	}
+
+	var coupons int
+	cfs := fcn.Cashflows(path)
+	for _, cf := range cfs {
+		if cf.Type == FixedCoupon || cf.Type == BarrierCoupon {
+			coupons++
+			require.True(t, cf.Date.After(obs[0]))
+		}
+	}
+	require.Equal(t, 4, coupons)
+	last := cfs[len(cfs)-1]
+	require.Equal(t, Redemption, last.Type)
+	require.InDelta(t, 0.90, last.Amount, 1e-12)
+}
+
+func TestFCNNonCall(t *testing.T) {
+	tNow, _ := time.Parse(Layout, "2023-01-17")
+	dates, err := util.GenerateCallableDates(tNow, 3, 1, 1)
+	require.NoError(t, err)
+	n := len(dates["mcdates"])
+
+	path := mc.MCPath{"AAPL": make([]float64, n)}
+	for i := range path["AAPL"] {
+		path["AAPL"][i] = 1.10
+	}
+
+	fcn := NewFCN([]string{"AAPL"}, 0.80, 0.12, 0.12, 0.12, 1.05, 0.70, 0.80, 3, 1, false, dates)
+	cfs := fcn.Cashflows(path)
+
+	// Coupons are paid on the first coupon date but the note only knocks out on the second
+	last := cfs[len(cfs)-1]
+	require.Equal(t, Redemption, last.Type)
+	require.True(t, last.Date.Equal(dates["cpndates"][1]))
+	require.True(t, cfs[0].Date.Equal(dates["cpndates"][0]))
+	require.Len(t, cfs, 7)
+}
+
+func TestFCNPhysicalSettlement(t *testing.T) {
+	tNow, _ := time.Parse(Layout, "2023-01-17")
+	dates, err := util.GenerateDates(tNow, 3, 1)
+	require.NoError(t, err)
+	n := len(dates["mcdates"])
+
+	path := mc.MCPath{"AAPL": make([]float64, n), "TSLA": make([]float64, n)}
+	for i := range path["AAPL"] {
+		path["AAPL"][i] = 0.90
+		path["TSLA"][i] = 0.60
+	}
+
+	fcn := NewFCN([]string{"AAPL", "TSLA"}, 0.80, 0, 0, 0, 1.05, 0.70, 0.80, 3, 1, false, dates)
+	require.Error(t, fcn.SetPhysicalSettlement(map[string]float64{"AAPL": 130.0}, 10000))
+	require.Error(t, fcn.SetPhysicalSettlement(map[string]float64{"AAPL": 130.0, "TSLA": 110.0}, 0))
+	require.NoError(t, fcn.SetPhysicalSettlement(map[string]float64{"AAPL": 130.0, "TSLA": 110.0}, 10000))
+
+	cfs := fcn.Cashflows(path)
+	delivery, cash := cfs[len(cfs)-2], cfs[len(cfs)-1]
+	require.Equal(t, Delivery, delivery.Type)
+	require.Equal(t, "TSLA", delivery.Ticker)
+	// 10000 / (0.80 * 110) = 113.6 shares per note, of which 113 are delivered
+	require.InDelta(t, 113.0/10000, delivery.Shares, 1e-12)
+	require.Equal(t, FractionalCash, cash.Type)
+	// Delivery and cash-out together are worth the cash settled redemption
+	require.InDelta(t, 0.60/0.80, delivery.Amount+cash.Amount, 1e-12)
+}
+
+func TestFCNCouponAccrual(t *testing.T) {
+	tNow, _ := time.Parse(Layout, "2023-01-17")
+	dates, err := util.GenerateDates(tNow, 12, 3)
+	require.NoError(t, err)
+	n := len(dates["mcdates"])
+
+	t.Run("QUARTERLY_COUPONS", func(t *testing.T) {
+		fcn := NewFCN([]string{"AAPL"}, 0.80, 0.10, 0.08, 0.12, 1.05, 0.70, 0.80, 12, 3, false, dates)
+		coupons := map[string]float64{}
+		for _, cf := range fcn.Cashflows(mc.MCPath{"AAPL": flat(n, 1.0)}) {
+			switch cf.Type {
+			case FixedCoupon:
+				require.InDelta(t, 0.03, cf.Amount, 1e-12)
+			case BarrierCoupon:
+				require.InDelta(t, 0.02, cf.Amount, 1e-12)
+			}
+			coupons[cf.Type] += cf.Amount
+		}
+		require.InDelta(t, 0.12, coupons[FixedCoupon], 1e-12)
+		require.InDelta(t, 0.08, coupons[BarrierCoupon], 1e-12)
+
+		// The same barrier coupon rate accrues alike on a Phoenix
+		phoenix := NewPhoenix([]string{"AAPL"}, 0.80, 0.08, 1.05, 0.70, 0.80, 12, 3, false, false, dates)
+		for _, cf := range phoenix.Cashflows(mc.MCPath{"AAPL": flat(n, 1.0)}) {
+			if cf.Type == BarrierCoupon {
+				require.InDelta(t, 0.02, cf.Amount, 1e-12)
+			}
+		}
+	})
+
+	t.Run("AUTOCALL_COUPON", func(t *testing.T) {
+		fcn := NewFCN([]string{"AAPL"}, 0.80, 0.10, 0.08, 0.12, 1.05, 0.70, 0.80, 12, 3, false, dates)
+		path := flat(n, 1.0)
+		for i, d := range dates["mcdates"] {
+			if d.Equal(dates["cpndates"][1]) {
+				path[i] = 1.10
+			}
+		}
+		var autocall Cashflow
+		for _, cf := range fcn.Cashflows(mc.MCPath{"AAPL": path}) {
+			if cf.Type == AutocallCoupon {
+				autocall = cf
+			}
+		}
+		require.True(t, autocall.Date.Equal(dates["cpndates"][1]))
+		require.InDelta(t, 0.05, autocall.Amount, 1e-12)
+
+		snowball := NewSnowball([]string{"AAPL"}, 0.80, 0.10, 1.05, 0.70, 12, 3, dates)
+		cfs := snowball.Cashflows(mc.MCPath{"AAPL": path})
+		require.Equal(t, AutocallCoupon, cfs[0].Type)
+		require.InDelta(t, autocall.Amount, cfs[0].Amount, 1e-12)
+	})
+}
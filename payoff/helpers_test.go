@@ -0,0 +1,10 @@
+package payoff
+
+// Path of n fixings all at v
+func flat(n int, v float64) []float64 {
+	p := make([]float64, n)
+	for i := range p {
+		p[i] = v
+	}
+	return p
+}
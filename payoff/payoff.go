@@ -0,0 +1,86 @@
+package payoff
+
+import (
+	"math"
+	"time"
+
+	"github.com/banachtech/spotted-zebra/mc"
+)
+
+// Risk-free rate used to discount path cashflows
+const Rate = 0.03
+
+// Product types understood by the pricer
+const (
+	ProductFCN                = "fcn"
+	ProductPhoenix            = "phoenix"
+	ProductReverseConvertible = "reverse_convertible"
+	ProductELN                = "eln"
+	ProductSnowball           = "snowball"
+	ProductAccumulator        = "accumulator"
+	ProductDecumulator        = "decumulator"
+)
+
+// Cashflow types
+const (
+	FixedCoupon    = "fixed_coupon"
+	BarrierCoupon  = "barrier_coupon"
+	AutocallCoupon = "autocall_coupon"
+	Redemption     = "redemption"
+	Accumulation   = "accumulation"
+	Delivery       = "delivery"
+	FractionalCash = "fractional_cash"
+	KnockIn        = "knock_in"
+	KnockOut       = "knock_out"
+)
+
+// Settlement modes on knock-in
+const (
+	Cash     = "cash"
+	Physical = "physical"
+)
+
+// Payoff interface to be satisfied by structured products priced by the monte-carlo engine.
+type Payoff interface {
+	// Observation dates on which the underlying basket must be simulated. The first date is the valuation date.
+	Dates() []time.Time
+	// Compute the discounted payout of a simulated basket path of price ratios
+	Payout(mc.MCPath) float64
+	// Compute the undiscounted cashflows paid on a simulated basket path of price ratios
+	Cashflows(mc.MCPath) []Cashflow
+}
+
+// A cashflow fixed by a product on a given observation date, per unit notional. It is paid on
+// PayDate when set, or on the observation date otherwise. Physical deliveries record the
+// delivered ticker and number of shares. Knock-in and knock-out events are recorded on the date
+// they occur with a zero amount.
+type Cashflow struct {
+	Date    time.Time `json:"date"`
+	PayDate time.Time `json:"payment_date"`
+	Type    string    `json:"type"`
+	Amount  float64   `json:"amount"`
+	Ticker  string    `json:"ticker,omitempty"`
+	Shares  float64   `json:"shares,omitempty"`
+}
+
+// Date the cashflow is paid
+func (cf Cashflow) Paid() time.Time {
+	if cf.PayDate.IsZero() {
+		return cf.Date
+	}
+	return cf.PayDate
+}
+
+// Discount a list of cashflows from their payment dates back to the valuation date t0.
+func PV(cfs []Cashflow, t0 time.Time) float64 {
+	out := 0.0
+	for _, cf := range cfs {
+		out += math.Exp(-Rate*YearFrac(t0, cf.Paid())) * cf.Amount
+	}
+	return out
+}
+
+// Year fraction between two dates on an ACT/365 basis.
+func YearFrac(t0, t1 time.Time) float64 {
+	return float64(t1.Unix()-t0.Unix()) / float64(60*60*24*365)
+}
@@ -0,0 +1,71 @@
+package payoff
+
+import (
+	"time"
+
+	"github.com/banachtech/spotted-zebra/mc"
+)
+
+// A Phoenix autocallable shares the FCN observation schedule and barriers. On each coupon date it pays
+// the conditional coupon if the worst-of performance is above the coupon barrier KC, and redeems
+// early if it is above the knock-out barrier KO. With memory, coupons missed on earlier dates are
+// paid on the next date the coupon barrier is met.
+type Phoenix struct {
+	FCN
+	Memory bool
+}
+
+var _ Payoff = (*Phoenix)(nil)
+
+// Constructor for Phoenix. The conditional coupon cpn is an annual rate.
+func NewPhoenix(stocks []string, k, cpn, ko, ki, kc float64, T, freq int, isEuro, memory bool, m map[string][]time.Time) *Phoenix {
+	f := NewFCN(stocks, k, 0, cpn, 0, ko, ki, kc, T, freq, isEuro, m)
+	return &Phoenix{FCN: *f, Memory: memory}
+}
+
+// Compute the discounted payout of the Phoenix on a basket path
+func (p *Phoenix) Payout(path mc.MCPath) float64 {
+	return PV(p.Cashflows(path), p.Dates()[0])
+}
+
+// Compute the cashflows of the Phoenix on a basket path
+func (p *Phoenix) Cashflows(path mc.MCPath) []Cashflow {
+	count, nko := p.elapsed()
+	// Coupons missed since the last coupon paid
+	missed := p.History.MissedCoupons
+	wop := p.Basket.Aggregate(path)
+
+	factor := p.periodFactor()
+
+	isKI, cfs := p.pastKnockIn()
+	for i, t := range p.Dates() {
+		if count < len(p.CpnDates) && t.Equal(p.CpnDates[count]) {
+			cpn := p.barrierCoupon(count, factor)
+			if wop[i] > p.couponBarrier(count) {
+				if p.Memory {
+					cpn += missed
+				}
+				missed = 0
+				cfs = append(cfs, Cashflow{Date: t, Type: BarrierCoupon, Amount: cpn})
+			} else {
+				missed += cpn
+			}
+			if p.isKODate(t, nko) {
+				if wop[i] > p.knockOutBarrier(nko) {
+					cfs = append(cfs, Cashflow{Date: t, Type: KnockOut})
+					return append(cfs, Cashflow{Date: t, Type: Redemption, Amount: 1.0})
+				}
+				nko++
+			}
+			count++
+		}
+
+		if p.KIMonitoring != KIEuropean && !isKI {
+			if wop[i] < p.KI {
+				isKI = true
+				cfs = append(cfs, Cashflow{Date: t, Type: KnockIn})
+			}
+		}
+	}
+	return append(cfs, p.redemption(path, wop, isKI)...)
+}
@@ -0,0 +1,75 @@
+package payoff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/banachtech/spotted-zebra/mc"
+	"github.com/banachtech/spotted-zebra/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPhoenixCashflows(t *testing.T) {
+	tNow, _ := time.Parse(Layout, "2023-01-17")
+	dates, err := util.GenerateDates(tNow, 3, 1)
+	require.NoError(t, err)
+	n := len(dates["mcdates"])
+
+	// Worst-of is below the coupon barrier until the last KO date
+	path := mc.MCPath{"AAPL": make([]float64, n), "TSLA": make([]float64, n)}
+	for i := range path["AAPL"] {
+		path["AAPL"][i] = 1.0
+		path["TSLA"][i] = 0.75
+	}
+	path["TSLA"][n-1] = 0.90
+
+	type testCases struct {
+		name   string
+		memory bool
+		coupon float64
+	}
+
+	for _, test := range []testCases{
+		{
+			name:   "MEMORY",
+			memory: true,
+			coupon: 3 * 0.12 / 12.0,
+		},
+		{
+			name:   "NO_MEMORY",
+			memory: false,
+			coupon: 0.12 / 12.0,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			p := NewPhoenix([]string{"AAPL", "TSLA"}, 0.80, 0.12, 1.05, 0.70, 0.80, 3, 1, false, test.memory, dates)
+			cfs := p.Cashflows(path)
+			require.Len(t, cfs, 2)
+			require.Equal(t, BarrierCoupon, cfs[0].Type)
+			require.InDelta(t, test.coupon, cfs[0].Amount, 1e-12)
+			require.Equal(t, Redemption, cfs[1].Type)
+			require.InDelta(t, 1.0, cfs[1].Amount, 1e-12)
+		})
+	}
+}
+
+func TestPhoenixCouponAccrual(t *testing.T) {
+	tNow, _ := time.Parse(Layout, "2023-01-17")
+	dates, err := util.GenerateDates(tNow, 12, 3)
+	require.NoError(t, err)
+	n := len(dates["mcdates"])
+	path := mc.MCPath{"AAPL": make([]float64, n)}
+	for i := range path["AAPL"] {
+		path["AAPL"][i] = 1.0
+	}
+
+	p := NewPhoenix([]string{"AAPL"}, 0.80, 0.12, 1.05, 0.70, 0.80, 12, 3, false, false, dates)
+	coupons := 0.0
+	for _, cf := range p.Cashflows(path) {
+		if cf.Type == BarrierCoupon {
+			require.InDelta(t, 0.03, cf.Amount, 1e-12)
+			coupons += cf.Amount
+		}
+	}
+	require.InDelta(t, 0.12, coupons, 1e-12)
+}
@@ -0,0 +1,77 @@
+package payoff
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/banachtech/spotted-zebra/mc"
+)
+
+// A payoff on a basket whose underlyings are postponed individually when an observation date is
+// a holiday on some of their exchanges. The basket is observed on the latest fixing date, on which
+// each underlying takes its price from its own fixing date.
+type Postponed struct {
+	Payoff
+	fixings map[string][]postponement
+}
+
+// Path index of an observation date and of the earlier fixing date of an underlying
+type postponement struct {
+	obs, fix int
+}
+
+var _ Payoff = (*Postponed)(nil)
+
+// Observe each ticker of a payoff on its own fixing dates, fixdates[ticker][i] being its fixing
+// date for the observation date obsdates[i]. Dates before the first date of the payoff are
+// already fixed and ignored.
+func NewPostponed(p Payoff, obsdates []time.Time, fixdates map[string][]time.Time) (*Postponed, error) {
+	idx := map[int64]int{}
+	for i, d := range p.Dates() {
+		idx[d.Unix()] = i
+	}
+	out := &Postponed{Payoff: p, fixings: map[string][]postponement{}}
+	for k, v := range fixdates {
+		if len(v) != len(obsdates) {
+			return nil, fmt.Errorf("%d fixing dates for %d observation dates of %s", len(v), len(obsdates), k)
+		}
+		for i, d := range v {
+			if d.After(obsdates[i]) {
+				return nil, fmt.Errorf("fixing date %s of %s is after the observation date %s", d.Format(Layout), k, obsdates[i].Format(Layout))
+			}
+			obs, ok := idx[obsdates[i].Unix()]
+			if !ok {
+				continue
+			}
+			fix, ok := idx[d.Unix()]
+			if ok && fix != obs {
+				out.fixings[k] = append(out.fixings[k], postponement{obs: obs, fix: fix})
+			}
+		}
+	}
+	return out, nil
+}
+
+// Compute the discounted payout of a simulated basket path
+func (p *Postponed) Payout(path mc.MCPath) float64 {
+	return PV(p.Cashflows(path), p.Dates()[0])
+}
+
+// Compute the undiscounted cashflows of the payoff on the path with each underlying observed on
+// its own fixing dates
+func (p *Postponed) Cashflows(path mc.MCPath) []Cashflow {
+	if len(p.fixings) == 0 {
+		return p.Payoff.Cashflows(path)
+	}
+	fixed := make(mc.MCPath, len(path))
+	for k, v := range path {
+		fixed[k] = v
+		if ps, ok := p.fixings[k]; ok {
+			fixed[k] = append([]float64{}, v...)
+			for _, x := range ps {
+				fixed[k][x.obs] = v[x.fix]
+			}
+		}
+	}
+	return p.Payoff.Cashflows(fixed)
+}
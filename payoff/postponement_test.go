@@ -0,0 +1,51 @@
+package payoff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/banachtech/spotted-zebra/mc"
+	"github.com/banachtech/spotted-zebra/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostponed(t *testing.T) {
+	tNow, _ := time.Parse(Layout, "2023-01-17")
+	dates, err := util.GenerateDates(tNow, 3, 1)
+	require.NoError(t, err)
+	fcn := NewFCN([]string{"AAPL", "TSLA"}, 0.80, 0.12, 0.12, 0.12, 1.05, 0.70, 0.80, 3, 1, false, dates)
+	n := len(dates["mcdates"])
+
+	// TSLA is fixed the day before the first coupon date, on which it falls below the barriers
+	cpndates := dates["cpndates"]
+	obs := 0
+	for i, d := range dates["mcdates"] {
+		if d.Equal(cpndates[0]) {
+			obs = i
+		}
+	}
+	fix := append([]time.Time{}, cpndates...)
+	fix[0] = dates["mcdates"][obs-1]
+	tsla := flat(n, 1.10)
+	tsla[obs] = 0.90
+	path := mc.MCPath{"AAPL": flat(n, 1.10), "TSLA": tsla}
+
+	postponed, err := NewPostponed(fcn, cpndates, map[string][]time.Time{"TSLA": fix})
+	require.NoError(t, err)
+	require.Equal(t, fcn.Dates(), postponed.Dates())
+
+	cfs := postponed.Cashflows(path)
+	require.True(t, cfs[len(cfs)-1].Date.Equal(cpndates[0]))
+	require.InDelta(t, PV(cfs, tNow), postponed.Payout(path), 1e-12)
+	require.Equal(t, 0.90, path["TSLA"][obs])
+
+	// Observed on the common date, the note does not knock out
+	cfs = fcn.Cashflows(path)
+	require.True(t, cfs[len(cfs)-1].Date.After(cpndates[0]))
+
+	_, err = NewPostponed(fcn, cpndates, map[string][]time.Time{"TSLA": fix[:1]})
+	require.Error(t, err)
+	fix[0] = cpndates[0].AddDate(0, 0, 1)
+	_, err = NewPostponed(fcn, cpndates, map[string][]time.Time{"TSLA": fix})
+	require.Error(t, err)
+}
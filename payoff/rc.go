@@ -0,0 +1,55 @@
+package payoff
+
+import (
+	"time"
+
+	"github.com/banachtech/spotted-zebra/mc"
+)
+
+// A worst-of reverse convertible pays a fixed coupon on each coupon date and redeems at maturity,
+// less the loss on a put struck at the strike if the worst-of performance knocked in. The put is
+// a plain European put when no knock-in barrier is given.
+type ReverseConvertible struct {
+	FCN
+}
+
+var _ Payoff = (*ReverseConvertible)(nil)
+
+// Constructor for ReverseConvertible. The fixed coupon cpn is an annual rate, and a zero ki gives a plain put.
+func NewReverseConvertible(stocks []string, k, cpn, ki float64, T, freq int, isEuro bool, m map[string][]time.Time) *ReverseConvertible {
+	if ki == 0 {
+		ki, isEuro = k, true
+	}
+	f := NewFCN(stocks, k, 0, 0, cpn, 0, ki, 0, T, freq, isEuro, m)
+	f.KODates = nil
+	return &ReverseConvertible{FCN: *f}
+}
+
+// Compute the discounted payout of the reverse convertible on a basket path
+func (r *ReverseConvertible) Payout(path mc.MCPath) float64 {
+	return PV(r.Cashflows(path), r.Dates()[0])
+}
+
+// Compute the cashflows of the reverse convertible on a basket path
+func (r *ReverseConvertible) Cashflows(path mc.MCPath) []Cashflow {
+	count, _ := r.elapsed()
+	wop := r.Basket.Aggregate(path)
+
+	factor := r.periodFactor()
+
+	isKI, cfs := r.pastKnockIn()
+	for i, t := range r.Dates() {
+		if count < len(r.CpnDates) && t.Equal(r.CpnDates[count]) {
+			cfs = append(cfs, Cashflow{Date: t, Type: FixedCoupon, Amount: r.fixedCoupon(count, factor)})
+			count++
+		}
+
+		if r.KIMonitoring != KIEuropean && !isKI {
+			if wop[i] < r.KI {
+				isKI = true
+				cfs = append(cfs, Cashflow{Date: t, Type: KnockIn})
+			}
+		}
+	}
+	return append(cfs, r.redemption(path, wop, isKI)...)
+}
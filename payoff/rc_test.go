@@ -0,0 +1,81 @@
+package payoff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/banachtech/spotted-zebra/mc"
+	"github.com/banachtech/spotted-zebra/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReverseConvertibleCashflows(t *testing.T) {
+	tNow, _ := time.Parse(Layout, "2023-01-17")
+	dates, err := util.GenerateDates(tNow, 3, 1)
+	require.NoError(t, err)
+	n := len(dates["mcdates"])
+
+	// Worst-of dips to 0.60 during the life of the note and finishes at 0.90
+	path := mc.MCPath{"AAPL": make([]float64, n), "TSLA": make([]float64, n)}
+	for i := range path["AAPL"] {
+		path["AAPL"][i] = 1.10
+		path["TSLA"][i] = 0.90
+	}
+	path["TSLA"][n/2] = 0.60
+
+	type testCases struct {
+		name       string
+		ki         float64
+		isEuro     bool
+		redemption float64
+		knockIn    bool
+	}
+
+	for _, test := range []testCases{
+		{name: "DAILY_KNOCK_IN", ki: 0.70, isEuro: false, redemption: 1.0 - (1.0-0.90)/1.0, knockIn: true},
+		{name: "EURO_KNOCK_IN", ki: 0.70, isEuro: true, redemption: 1.0},
+		{name: "PLAIN_PUT", ki: 0, isEuro: false, redemption: 1.0 - (1.0-0.90)/1.0, knockIn: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			rc := NewReverseConvertible([]string{"AAPL", "TSLA"}, 1.0, 0.12, test.ki, 3, 1, test.isEuro, dates)
+			var cfs []Cashflow
+			knockIn := false
+			for _, cf := range rc.Cashflows(path) {
+				if cf.Type == KnockIn {
+					knockIn = true
+					continue
+				}
+				cfs = append(cfs, cf)
+			}
+			require.Equal(t, test.knockIn, knockIn)
+			require.Len(t, cfs, 4)
+			for _, cf := range cfs[:3] {
+				require.Equal(t, FixedCoupon, cf.Type)
+				require.InDelta(t, 0.01, cf.Amount, 1e-12)
+			}
+			require.Equal(t, Redemption, cfs[3].Type)
+			require.InDelta(t, test.redemption, cfs[3].Amount, 1e-12)
+		})
+	}
+}
+
+func TestReverseConvertibleCouponAccrual(t *testing.T) {
+	tNow, _ := time.Parse(Layout, "2023-01-17")
+	dates, err := util.GenerateDates(tNow, 12, 3)
+	require.NoError(t, err)
+	n := len(dates["mcdates"])
+	path := mc.MCPath{"AAPL": make([]float64, n)}
+	for i := range path["AAPL"] {
+		path["AAPL"][i] = 1.0
+	}
+
+	rc := NewReverseConvertible([]string{"AAPL"}, 1.0, 0.12, 0.70, 12, 3, false, dates)
+	coupons := 0.0
+	for _, cf := range rc.Cashflows(path) {
+		if cf.Type == FixedCoupon {
+			require.InDelta(t, 0.03, cf.Amount, 1e-12)
+			coupons += cf.Amount
+		}
+	}
+	require.InDelta(t, 0.12, coupons, 1e-12)
+}
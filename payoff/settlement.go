@@ -0,0 +1,49 @@
+package payoff
+
+import (
+	"time"
+
+	"github.com/banachtech/spotted-zebra/mc"
+)
+
+// A payoff paying each cashflow on the payment date of the observation date fixing it, such as
+// a settlement lag of a few business days after observation. Barrier events are not paid.
+type Settled struct {
+	Payoff
+	payDates map[int64]time.Time
+}
+
+var _ Payoff = (*Settled)(nil)
+
+// Pay the cashflows of a payoff on the payment dates given by pay for each of its observation dates
+func NewSettled(p Payoff, pay func(time.Time) time.Time) *Settled {
+	s := &Settled{Payoff: p, payDates: map[int64]time.Time{}}
+	for _, d := range p.Dates() {
+		s.payDates[d.Unix()] = pay(d)
+	}
+	return s
+}
+
+// Payment date of cashflows fixed on an observation date
+func (s *Settled) PayDate(d time.Time) time.Time {
+	if t, ok := s.payDates[d.Unix()]; ok {
+		return t
+	}
+	return d
+}
+
+// Compute the discounted payout of a simulated basket path, discounting from the payment dates
+func (s *Settled) Payout(path mc.MCPath) float64 {
+	return PV(s.Cashflows(path), s.Dates()[0])
+}
+
+// Compute the undiscounted cashflows of the payoff with their payment dates
+func (s *Settled) Cashflows(path mc.MCPath) []Cashflow {
+	cfs := s.Payoff.Cashflows(path)
+	for i, cf := range cfs {
+		if cf.Type != KnockIn && cf.Type != KnockOut {
+			cfs[i].PayDate = s.PayDate(cf.Date)
+		}
+	}
+	return cfs
+}
@@ -0,0 +1,39 @@
+package payoff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/banachtech/spotted-zebra/calendar"
+	"github.com/banachtech/spotted-zebra/mc"
+	"github.com/banachtech/spotted-zebra/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSettled(t *testing.T) {
+	tNow, _ := time.Parse(Layout, "2023-01-17")
+	dates, err := util.GenerateDates(tNow, 3, 1)
+	require.NoError(t, err)
+	fcn := NewFCN([]string{"AAPL", "TSLA"}, 0.80, 0.12, 0.12, 0.12, 1.05, 0.70, 0.80, 3, 1, false, dates)
+	settled := NewSettled(fcn, func(d time.Time) time.Time { return calendar.NYSE.AddBusinessDays(d, 2) })
+	require.Equal(t, fcn.Dates(), settled.Dates())
+
+	n := len(dates["mcdates"])
+	path := mc.MCPath{"AAPL": flat(n, 1.00), "TSLA": flat(n, 0.60)}
+
+	cfs := settled.Cashflows(path)
+	require.Len(t, cfs, len(fcn.Cashflows(path)))
+	for _, cf := range cfs {
+		if cf.Type == KnockIn {
+			require.True(t, cf.PayDate.IsZero())
+			continue
+		}
+		require.Equal(t, calendar.NYSE.AddBusinessDays(cf.Date, 2), cf.PayDate)
+	}
+	// 2023-04-17 is a Monday, paid on the Wednesday
+	last := cfs[len(cfs)-1]
+	require.Equal(t, "2023-04-19", last.Paid().Format(Layout))
+
+	require.Less(t, settled.Payout(path), fcn.Payout(path))
+	require.InDelta(t, PV(cfs, tNow), settled.Payout(path), 1e-12)
+}
@@ -0,0 +1,68 @@
+package payoff
+
+import (
+	"math"
+	"time"
+
+	"github.com/banachtech/spotted-zebra/mc"
+)
+
+// A snowball note accrues its coupon every period until it knocks out, when the principal and all
+// accrued coupons are paid. Knock-in is observed daily. At maturity a note that never knocked out
+// pays the full accrued coupon if it never knocked in, and otherwise converts into the worst
+// performer at the strike with no coupon.
+type Snowball struct {
+	FCN
+}
+
+var _ Payoff = (*Snowball)(nil)
+
+// Constructor for Snowball. The coupon cpn is an annual rate.
+func NewSnowball(stocks []string, k, cpn, ko, ki float64, T, freq int, m map[string][]time.Time) *Snowball {
+	f := NewFCN(stocks, k, cpn, 0, 0, ko, ki, 0, T, freq, false, m)
+	return &Snowball{FCN: *f}
+}
+
+// Compute the discounted payout of the snowball on a basket path
+func (s *Snowball) Payout(path mc.MCPath) float64 {
+	return PV(s.Cashflows(path), s.Dates()[0])
+}
+
+// Compute the cashflows of the snowball on a basket path
+func (s *Snowball) Cashflows(path mc.MCPath) []Cashflow {
+	count, nko := s.elapsed()
+	wop := s.Basket.Aggregate(path)
+	factor := s.periodFactor()
+
+	isKI, cfs := s.pastKnockIn()
+	for i, t := range s.Dates() {
+		if count < len(s.CpnDates) && t.Equal(s.CpnDates[count]) {
+			if s.isKODate(t, nko) {
+				if wop[i] > s.knockOutBarrier(nko) {
+					return append(cfs,
+						Cashflow{Date: t, Type: AutocallCoupon, Amount: float64(count+1) * factor * s.Coupon},
+						Cashflow{Date: t, Type: KnockOut},
+						Cashflow{Date: t, Type: Redemption, Amount: 1.0},
+					)
+				}
+				nko++
+			}
+			count++
+		}
+
+		if !isKI && wop[i] < s.KI {
+			isKI = true
+			cfs = append(cfs, Cashflow{Date: t, Type: KnockIn})
+		}
+	}
+
+	obs := s.Dates()
+	T := len(wop) - 1
+	if isKI {
+		return append(cfs, Cashflow{Date: obs[T], Type: Redemption, Amount: math.Min(1.0, wop[T]/s.Strike)})
+	}
+	return append(cfs,
+		Cashflow{Date: obs[T], Type: AutocallCoupon, Amount: float64(count) * factor * s.Coupon},
+		Cashflow{Date: obs[T], Type: Redemption, Amount: 1.0},
+	)
+}
@@ -0,0 +1,61 @@
+package payoff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/banachtech/spotted-zebra/mc"
+	"github.com/banachtech/spotted-zebra/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnowballCashflows(t *testing.T) {
+	tNow, _ := time.Parse(Layout, "2023-01-17")
+	dates, err := util.GenerateDates(tNow, 3, 1)
+	require.NoError(t, err)
+	n := len(dates["mcdates"])
+	snowball := NewSnowball([]string{"AAPL"}, 1.0, 0.12, 1.03, 0.75, 3, 1, dates)
+
+	ko := flat(n, 1.0)
+	ko[len(ko)-1] = 1.05
+	ki := flat(n, 0.95)
+	ki[n/2] = 0.70
+	ki[n-1] = 0.80
+
+	type testCases struct {
+		name    string
+		path    mc.MCPath
+		amounts []float64
+	}
+
+	for _, test := range []testCases{
+		{name: "KNOCK_OUT", path: mc.MCPath{"AAPL": ko}, amounts: []float64{0.03, 0, 1.0}},
+		{name: "NO_EVENT", path: mc.MCPath{"AAPL": flat(n, 0.95)}, amounts: []float64{0.03, 1.0}},
+		{name: "KNOCK_IN", path: mc.MCPath{"AAPL": ki}, amounts: []float64{0, 0.80}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			cfs := snowball.Cashflows(test.path)
+			require.Len(t, cfs, len(test.amounts))
+			for i := range cfs {
+				require.InDelta(t, test.amounts[i], cfs[i].Amount, 1e-12)
+			}
+		})
+	}
+}
+
+func TestSnowballCouponAccrual(t *testing.T) {
+	tNow, _ := time.Parse(Layout, "2023-01-17")
+	dates, err := util.GenerateDates(tNow, 12, 3)
+	require.NoError(t, err)
+	n := len(dates["mcdates"])
+	path := mc.MCPath{"AAPL": make([]float64, n)}
+	for i := range path["AAPL"] {
+		path["AAPL"][i] = 0.95
+	}
+
+	snowball := NewSnowball([]string{"AAPL"}, 1.0, 0.12, 1.03, 0.75, 12, 3, dates)
+	cfs := snowball.Cashflows(path)
+	require.Len(t, cfs, 2)
+	require.Equal(t, AutocallCoupon, cfs[0].Type)
+	require.InDelta(t, 0.12, cfs[0].Amount, 1e-12)
+}
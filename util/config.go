@@ -5,11 +5,13 @@ import "github.com/spf13/viper"
 // Config stores all configuration of the application.
 // The values are read by viper from a config file or environment variable.
 type Config struct {
-	DBDriver        string `mapstructure:"DB_DRIVER"`
-	DBSource        string `mapstructure:"DB_SOURCE"`
-	ServerAddress   string `mapstructure:"SERVER_ADDRESS"`
-	PolygonKey      string `mapstructure:"POLYGON_API_KEY"`
-	AlphaVantageKey string `mapstructure:"ALPHAVANTAGE_API_KEY"`
+	DBDriver        string   `mapstructure:"DB_DRIVER"`
+	DBSource        string   `mapstructure:"DB_SOURCE"`
+	ServerAddress   string   `mapstructure:"SERVER_ADDRESS"`
+	PolygonKey      string   `mapstructure:"POLYGON_API_KEY"`
+	AlphaVantageKey string   `mapstructure:"ALPHAVANTAGE_API_KEY"`
+	CalendarDir     string   `mapstructure:"CALENDAR_DIR"`
+	AdminPrefixes   []string `mapstructure:"ADMIN_PREFIXES"`
 }
 
 // LoadConfig reads configuration from file or environment variables.
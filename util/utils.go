@@ -2,9 +2,12 @@ package util
 
 import (
 	"errors"
+	"fmt"
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/banachtech/spotted-zebra/calendar"
 )
 
 type Holidays struct {
@@ -15,72 +18,44 @@ type Holidays struct {
 
 const Layout = "2006-01-02"
 
-var NYSE = []string{"2022-01-01", "2022-01-17", "2022-02-21", "2022-04-15", "2022-05-30", "2022-06-20", "2022-07-04", "2022-09-05", "2022-11-24", "2022-12-26", "2023-01-02", "2023-01-16", "2023-02-20", "2023-04-07", "2023-05-29", "2023-06-19", "2023-07-04", "2023-09-04", "2023-11-23", "2023-12-25", "2024-01-01", "2024-01-15", "2024-02-19", "2024-07-04", "2024-09-02", "2024-11-28", "2024-12-25"}
-
-// Convert holidays from string to time.Time format
-func Hols(s []string) ([]time.Time, error) {
-	h := make([]time.Time, len(s))
-	var err error
-	var d time.Time
-	for i, v := range s {
-		d, err = time.Parse("2006-01-02", v)
-		if err != nil {
-			return nil, err
-		}
-		h[i] = d
-	}
-	return h, err
-}
-
-func IsHol(d time.Time, hols []time.Time) bool {
-	if hols == nil {
-		return false
-	}
-	for _, v := range hols {
-		if d.Equal(v) {
-			return true
-		}
-	}
-	return false
+// Return a map of monte-carlo and knock-out barrier observation dates.
+// Frequency (freq) and tenor arguments are in number of months.
+func GenerateDates(start time.Time, tenor, freq int) (map[string][]time.Time, error) {
+	return GenerateCallableDates(start, tenor, freq, 0)
 }
 
-func IsWeekday(d time.Time) bool {
-	if d.Weekday() > 0 && d.Weekday() < 6 {
-		return true
-	}
-	return false
-}
+// Disruption rules for baskets of underlyings listed on several exchanges
+const (
+	// Observe every underlying on the next day all exchanges are open
+	CommonPostponement = "common"
+	// Observe each underlying on the next day its own exchange is open and the basket on the latest
+	IndividualPostponement = "individual"
+)
 
-func AdjustFollowing(d time.Time, hols []time.Time) time.Time {
-	for {
-		if IsHol(d, hols) || !IsWeekday(d) {
-			d = d.AddDate(0, 0, 1)
-		} else {
-			return d
-		}
-	}
+// Rules rolling scheduled observation dates onto business days. Disruption sets how dates are
+// postponed for baskets on several exchanges, Convention is a calendar business day convention
+// and EOM applies the end-of-month rule when rolling months.
+type DateRule struct {
+	Disruption string
+	Convention string
+	EOM        bool
 }
 
-// Return a list of business days from (and including) a start date to (and including) an end date according to a holiday calendar
-func ListBusinessDates(start time.Time, end time.Time, hols []time.Time) ([]time.Time, error) {
-	if end.Before(start) {
-		err := errors.New("end date must be later than start date")
-		return nil, err
-	}
-	var out = []time.Time{start}
-	for {
-		start = AdjustFollowing(start.AddDate(0, 0, 1), hols)
-		if start.After(end) {
-			return out, nil
-		}
-		out = append(out, start)
-	}
+// Return a map of monte-carlo, coupon and knock-out barrier observation dates on the default calendar.
+// Frequency (freq) and tenor arguments are in number of months. Knock-out is not observed on the
+// first nonCall coupon dates.
+func GenerateCallableDates(start time.Time, tenor, freq, nonCall int) (map[string][]time.Time, error) {
+	return GenerateBasketDates(start, tenor, freq, nonCall, []*calendar.Calendar{calendar.Default()}, DateRule{})
 }
 
-// Return a map of monte-carlo and knock-out barrier observation dates.
-// Frequency (freq) and tenor arguments are in number of months.
-func GenerateDates(start time.Time, tenor, freq int) (map[string][]time.Time, error) {
-	out := make(map[string][]time.Time, 2)
+// Return a map of monte-carlo, coupon and knock-out barrier observation dates for a basket with
+// an exchange calendar per underlying. Scheduled dates are rolled by the date rule. With common
+// postponement they are rolled on the days all exchanges are open, which are the monte-carlo
+// dates. With individual postponement each underlying is rolled on its own calendar, the coupon
+// date is the latest of them and the monte-carlo dates are the days any exchange is open.
+// Unadjusted coupon dates falling on a holiday are added to the monte-carlo dates.
+func GenerateBasketDates(start time.Time, tenor, freq, nonCall int, cals []*calendar.Calendar, rule DateRule) (map[string][]time.Time, error) {
+	out := make(map[string][]time.Time, 3)
 	if freq <= 0 || tenor <= 0 {
 		return nil, errors.New("maturity or frequency cannot be 0")
 	}
@@ -88,23 +63,95 @@ func GenerateDates(start time.Time, tenor, freq int) (map[string][]time.Time, er
 		return nil, errors.New("maturity must be greater than frequency")
 	}
 	n := tenor / freq
-	kodates := make([]time.Time, n)
-	hols, err := Hols(NYSE)
-	if err != nil {
-		return nil, err
+	if nonCall < 0 || nonCall >= n {
+		return nil, errors.New("non-call periods must be less than the number of coupon periods")
+	}
+	if len(cals) == 0 {
+		return nil, errors.New("at least one calendar is required")
 	}
-	for i := 0; i < n; i++ {
-		kodates[i] = AdjustFollowing(start.AddDate(0, (i+1)*freq, 0), hols)
+
+	// Calendars on which each scheduled date is rolled, and on which the basket is simulated
+	var rolls []*calendar.Calendar
+	switch rule.Disruption {
+	case "", CommonPostponement:
+		rolls = []*calendar.Calendar{calendar.Join("", cals...)}
+	case IndividualPostponement:
+		rolls = cals
+	default:
+		return nil, fmt.Errorf("unsupported disruption rule: %s", rule.Disruption)
 	}
-	mcdates, err := ListBusinessDates(start, kodates[len(kodates)-1], hols)
+
+	cpndates := make([]time.Time, n)
+	for _, c := range rolls {
+		dates, err := ScheduleDates(start, tenor, freq, c, rule)
+		if err != nil {
+			return nil, err
+		}
+		for i, t := range dates {
+			if t.After(cpndates[i]) {
+				cpndates[i] = t
+			}
+		}
+	}
+	for i := 1; i < n; i++ {
+		if !cpndates[i].After(cpndates[i-1]) {
+			return nil, errors.New("coupon dates must be strictly increasing")
+		}
+	}
+
+	mcdates, err := unionBusinessDates(start, cpndates[len(cpndates)-1], rolls)
 	if err != nil {
 		return nil, err
 	}
-	out["mcdates"] = mcdates
-	out["kodates"] = kodates
+	out["mcdates"] = mergeDates(mcdates, cpndates)
+	out["cpndates"] = cpndates
+	out["kodates"] = cpndates[nonCall:]
 	return out, err
 }
 
+// Return the dates every freq months from start up to tenor months, rolled on a calendar by the
+// date rule. These are the fixing dates of an underlying listed on that calendar.
+func ScheduleDates(start time.Time, tenor, freq int, cal *calendar.Calendar, rule DateRule) ([]time.Time, error) {
+	if freq <= 0 {
+		return nil, errors.New("frequency cannot be 0")
+	}
+	out := make([]time.Time, tenor/freq)
+	for i := range out {
+		t, err := cal.Adjust(cal.AddMonths(start, (i+1)*freq, rule.EOM), rule.Convention)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = t
+	}
+	return out, nil
+}
+
+// Start date followed by the days up to end on which any calendar is a business day
+func unionBusinessDates(start, end time.Time, cals []*calendar.Calendar) ([]time.Time, error) {
+	var all []time.Time
+	for _, c := range cals {
+		dates, err := c.BusinessDates(start, end)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, dates...)
+	}
+	return mergeDates(all[:1], all[1:]), nil
+}
+
+// Merge dates into sorted dates, dropping duplicates
+func mergeDates(dates, other []time.Time) []time.Time {
+	all := append(append([]time.Time{}, dates...), other...)
+	sort.Slice(all, func(i, j int) bool { return all[i].Before(all[j]) })
+	out := all[:1]
+	for _, d := range all[1:] {
+		if d.After(out[len(out)-1]) {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
 // Minimum of a slice
 func MinSlice(a []float64) float64 {
 	var m float64
@@ -0,0 +1,127 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/banachtech/spotted-zebra/calendar"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateCallableDates(t *testing.T) {
+	start, _ := time.Parse(Layout, "2023-01-17")
+
+	type testCases struct {
+		name    string
+		tenor   int
+		freq    int
+		nonCall int
+		nCpn    int
+		nKO     int
+		isErr   bool
+	}
+
+	for _, test := range []testCases{
+		{name: "CALLABLE", tenor: 12, freq: 1, nonCall: 0, nCpn: 12, nKO: 12},
+		{name: "NON_CALL", tenor: 12, freq: 1, nonCall: 3, nCpn: 12, nKO: 9},
+		{name: "NON_CALL_TOO_LONG", tenor: 12, freq: 3, nonCall: 4, isErr: true},
+		{name: "NEGATIVE_NON_CALL", tenor: 12, freq: 3, nonCall: -1, isErr: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			dates, err := GenerateCallableDates(start, test.tenor, test.freq, test.nonCall)
+			if test.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, dates["cpndates"], test.nCpn)
+			require.Len(t, dates["kodates"], test.nKO)
+			require.True(t, dates["kodates"][0].Equal(dates["cpndates"][test.nonCall]))
+			require.True(t, dates["mcdates"][len(dates["mcdates"])-1].Equal(dates["cpndates"][test.nCpn-1]))
+		})
+	}
+}
+
+func TestGenerateCallableDatesUploaded(t *testing.T) {
+	defer calendar.Register(calendar.NYSE)
+	cal, err := calendar.FromDates(calendar.DefaultName, []string{"2023-02-17"})
+	require.NoError(t, err)
+	calendar.Register(cal)
+
+	start, _ := time.Parse(Layout, "2023-01-17")
+	dates, err := GenerateCallableDates(start, 3, 1, 0)
+	require.NoError(t, err)
+	require.Equal(t, "2023-02-20", dates["cpndates"][0].Format(Layout))
+}
+
+func TestGenerateBasketDates(t *testing.T) {
+	// Coupon date scheduled on 2024-05-01, a holiday on XETRA but not on NYSE
+	start, _ := time.Parse(Layout, "2024-04-01")
+	cals := []*calendar.Calendar{calendar.NYSE, calendar.XETRA}
+
+	type testCases struct {
+		name       string
+		disruption string
+		cpn        string
+		nmc        int
+		isErr      bool
+	}
+
+	for _, test := range []testCases{
+		{name: "COMMON", disruption: CommonPostponement, cpn: "2024-05-02", nmc: 23},
+		{name: "DEFAULT", cpn: "2024-05-02", nmc: 23},
+		{name: "INDIVIDUAL", disruption: IndividualPostponement, cpn: "2024-05-02", nmc: 24},
+		{name: "UNSUPPORTED", disruption: "preceding", isErr: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			dates, err := GenerateBasketDates(start, 1, 1, 0, cals, DateRule{Disruption: test.disruption})
+			if test.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.cpn, dates["cpndates"][0].Format(Layout))
+			require.Len(t, dates["mcdates"], test.nmc)
+			require.True(t, dates["mcdates"][len(dates["mcdates"])-1].Equal(dates["cpndates"][0]))
+		})
+	}
+}
+
+func TestScheduleDates(t *testing.T) {
+	// Coupon date scheduled on 2024-05-01, a holiday on XETRA but not on NYSE
+	start, _ := time.Parse(Layout, "2024-04-01")
+	nyse, err := ScheduleDates(start, 1, 1, calendar.NYSE, DateRule{})
+	require.NoError(t, err)
+	require.Equal(t, "2024-05-01", nyse[0].Format(Layout))
+	xetra, err := ScheduleDates(start, 1, 1, calendar.XETRA, DateRule{})
+	require.NoError(t, err)
+	require.Equal(t, "2024-05-02", xetra[0].Format(Layout))
+}
+
+func TestDateRule(t *testing.T) {
+	// Month ends from 2024-01-31, with 2024-03-29 Good Friday and 2024-03-31 a Sunday
+	start, _ := time.Parse(Layout, "2024-01-31")
+	cals := []*calendar.Calendar{calendar.NYSE}
+
+	type testCases struct {
+		name string
+		rule DateRule
+		cpn  []string
+	}
+
+	for _, test := range []testCases{
+		{name: "FOLLOWING", rule: DateRule{}, cpn: []string{"2024-02-29", "2024-04-01", "2024-04-30"}},
+		{name: "MODIFIED_FOLLOWING", rule: DateRule{Convention: calendar.ModifiedFollowing}, cpn: []string{"2024-02-29", "2024-03-28", "2024-04-30"}},
+		{name: "UNADJUSTED", rule: DateRule{Convention: calendar.Unadjusted}, cpn: []string{"2024-02-29", "2024-03-31", "2024-04-30"}},
+		{name: "EOM", rule: DateRule{EOM: true}, cpn: []string{"2024-02-29", "2024-03-28", "2024-04-30"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			dates, err := GenerateBasketDates(start, 3, 1, 0, cals, test.rule)
+			require.NoError(t, err)
+			for i, v := range dates["cpndates"] {
+				require.Equal(t, test.cpn[i], v.Format(Layout))
+				require.True(t, IsIn(v, dates["mcdates"]))
+			}
+		})
+	}
+}